@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"strings"
 
@@ -49,8 +50,19 @@ example
 	cmd := args[0]
 	cmdArgs := args[1:]
 
-	err := godotenv.Exec(envFilenames, cmd, cmdArgs, true, overload)
+	status, err := godotenv.ExecResult(envFilenames, cmd, cmdArgs, true, overload)
 	if err != nil {
 		log.Fatal(err)
 	}
+	os.Exit(exitCode(status))
+}
+
+// exitCode is the code to exit with for godotenv to wrap the command transparently:
+// its own exit code, or 128 plus the signal number when a signal terminated it, as
+// shells report it.
+func exitCode(status godotenv.ExecStatus) int {
+	if status.Signaled {
+		return 128 + int(status.Signal)
+	}
+	return status.ExitCode
 }
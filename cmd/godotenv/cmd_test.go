@@ -0,0 +1,30 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/AzraelSec/godotenv"
+)
+
+func TestExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on /bin/sh")
+	}
+
+	status, err := godotenv.ExecResult([]string{"../../fixtures/plain.env"}, "/bin/sh", []string{"-c", "exit 3"}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := exitCode(status); code != 3 {
+		t.Errorf("Expected to exit with the code of the command, 3, got %d", code)
+	}
+
+	status, err = godotenv.ExecResult([]string{"../../fixtures/plain.env"}, "/bin/sh", []string{"-c", "kill -TERM $$"}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := exitCode(status); code != 143 {
+		t.Errorf("Expected a command killed by SIGTERM to exit with 143, got %d", code)
+	}
+}
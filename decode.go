@@ -0,0 +1,248 @@
+package godotenv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = make(map[reflect.Type]func(string) (interface{}, error))
+)
+
+// RegisterConverter makes Decode parse the fields of type t, and the pointers to
+// it, with convert, whose result must be assignable to t:
+//
+//	godotenv.RegisterConverter(reflect.TypeOf(LogLevel(0)), func(raw string) (interface{}, error) {
+//		return parseLogLevel(raw)
+//	})
+//
+// Converters take precedence over the types the decoder supports on its own. A
+// nil convert removes the converter registered for t.
+func RegisterConverter(t reflect.Type, convert func(raw string) (interface{}, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	if convert == nil {
+		delete(converters, t)
+		return
+	}
+	converters[t] = convert
+}
+
+func converterFor(t reflect.Type) func(string) (interface{}, error) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	return converters[t]
+}
+
+// Decode reads an env file from io.Reader and stores its values in the struct pointed to by v.
+//
+// Fields are matched to keys through their `env` tag, fields without one are left untouched:
+//
+//	type Config struct {
+//		Port    int           `env:"PORT" default:"8080"`
+//		Debug   bool          `env:"DEBUG"`
+//		Timeout time.Duration `env:"TIMEOUT"`
+//		Hosts   []string      `env:"HOSTS"`
+//		Token   *string       `env:"TOKEN"`
+//	}
+//
+// Supported field types are strings, ints, uints, bools, floats, time.Duration,
+// url.URL, []string and the types implementing
+// encoding.TextUnmarshaler, such as net.IP or time.Time, as well as pointers to
+// any of them. Bools are read the same way as GetBool does. Other types can be
+// supported with RegisterConverter.
+//
+// Slices are read from a comma separated value, split on the field's `delim` tag
+// instead when it is set. With an `expand:"indexed"` tag, they are gathered from
+// the keys suffixed with consecutive indexes instead, such as HOSTS_0, HOSTS_1
+// and so on for a field tagged `env:"HOSTS" expand:"indexed"`.
+//
+// A key missing from the file falls back to the field's `default` tag. If there is no
+// default the field must be a pointer, which is then left nil, otherwise Decode errors.
+func Decode(r io.Reader, v interface{}) error {
+	envMap, err := Parse(r)
+	if err != nil {
+		return err
+	}
+
+	return decodeMap(envMap, v)
+}
+
+func decodeMap(envMap map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("decode target must be a non-nil pointer to a struct")
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok || key == "" || field.PkgPath != "" {
+			continue
+		}
+
+		switch expand := field.Tag.Get("expand"); expand {
+		case "":
+		case "indexed":
+			if items, ok := indexedValues(envMap, key); ok {
+				if err := setIndexed(rv.Field(i), items); err != nil {
+					return fmt.Errorf("invalid field %s: %v", field.Name, err)
+				}
+				continue
+			}
+		default:
+			return fmt.Errorf("invalid field %s: unknown expand strategy %q", field.Name, expand)
+		}
+
+		raw, ok := envMap[key]
+		if !ok {
+			raw, ok = field.Tag.Lookup("default")
+		}
+		if !ok {
+			if field.Type.Kind() == reflect.Ptr {
+				continue
+			}
+			return fmt.Errorf("missing value for field %s: key %q is not set and has no default", field.Name, key)
+		}
+
+		delim := field.Tag.Get("delim")
+		if delim == "" {
+			delim = ","
+		}
+		if err := setField(rv.Field(i), raw, delim); err != nil {
+			return fmt.Errorf("invalid value for field %s from key %q: %s", field.Name, key, redactMessage(key, raw, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw, delim string) error {
+	if convert := converterFor(field.Type()); convert != nil {
+		v, err := convert(raw)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || !rv.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("converter for %s returned a %T", field.Type(), v)
+		}
+		field.Set(rv)
+		return nil
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setField(ptr.Elem(), raw, delim); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if field.Type() == urlType {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		var items []string
+		if raw != "" {
+			items = strings.Split(raw, delim)
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+		}
+		field.Set(reflect.ValueOf(items).Convert(field.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+// indexedValues returns the values of key_0, key_1 and so on, up to the first
+// missing index, reporting whether key_0 is set at all.
+func indexedValues(envMap map[string]string, key string) ([]string, bool) {
+	var items []string
+	for i := 0; ; i++ {
+		value, ok := envMap[key+"_"+strconv.Itoa(i)]
+		if !ok {
+			return items, len(items) > 0
+		}
+		items = append(items, value)
+	}
+}
+
+// setIndexed sets field, which must be a []string, to items.
+func setIndexed(field reflect.Value, items []string) error {
+	if field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.String {
+		return fmt.Errorf("indexed keys can't be stored in a %s", field.Type())
+	}
+	field.Set(reflect.ValueOf(items).Convert(field.Type()))
+	return nil
+}
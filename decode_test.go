@@ -0,0 +1,185 @@
+package godotenv
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	type config struct {
+		Name    string        `env:"NAME"`
+		Port    int           `env:"PORT" default:"8080"`
+		Debug   bool          `env:"DEBUG"`
+		Ratio   float64       `env:"RATIO"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Hosts   []string      `env:"HOSTS"`
+		Token   *string       `env:"TOKEN"`
+		Secret  *string       `env:"SECRET"`
+		Ignored string
+	}
+
+	input := "NAME=godotenv\nDEBUG=true\nRATIO=0.5\nTIMEOUT=1m30s\nHOSTS=a, b,c\nTOKEN=abc"
+	var cfg config
+	if err := Decode(strings.NewReader(input), &cfg); err != nil {
+		t.Fatalf("Expected decode to succeed, got %v", err)
+	}
+
+	token := "abc"
+	expected := config{
+		Name:    "godotenv",
+		Port:    8080,
+		Debug:   true,
+		Ratio:   0.5,
+		Timeout: 90 * time.Second,
+		Hosts:   []string{"a", "b", "c"},
+		Token:   &token,
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+
+	cases := map[string]struct {
+		input  string
+		target interface{}
+		want   string
+	}{
+		"missing key without default": {
+			input:  "OTHER=1",
+			target: &config{},
+			want:   "Port",
+		},
+		"value of the wrong type": {
+			input:  "PORT=notanumber",
+			target: &config{},
+			want:   `"PORT"`,
+		},
+		"target is not a pointer": {
+			input:  "PORT=1",
+			target: config{},
+			want:   "pointer to a struct",
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			err := Decode(strings.NewReader(c.input), c.target)
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Errorf("Expected error to mention %q, got %q", c.want, err)
+			}
+		})
+	}
+}
+
+type logLevel int
+
+func TestDecodeCustomTypes(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(logLevel(0)), func(raw string) (interface{}, error) {
+		switch raw {
+		case "debug":
+			return logLevel(0), nil
+		case "info":
+			return logLevel(1), nil
+		}
+		return nil, fmt.Errorf("unknown level %q", raw)
+	})
+	defer RegisterConverter(reflect.TypeOf(logLevel(0)), nil)
+
+	type config struct {
+		Endpoint *url.URL  `env:"ENDPOINT"`
+		Host     net.IP    `env:"HOST"`
+		Since    time.Time `env:"SINCE"`
+		Level    logLevel  `env:"LEVEL"`
+		Fallback *logLevel `env:"FALLBACK"`
+	}
+
+	input := "ENDPOINT=https://example.com/api\nHOST=10.0.0.1\nSINCE=2024-01-02T03:04:05Z\nLEVEL=info\nFALLBACK=debug"
+	var cfg config
+	if err := Decode(strings.NewReader(input), &cfg); err != nil {
+		t.Fatalf("Expected decode to succeed, got %v", err)
+	}
+
+	if cfg.Endpoint == nil || cfg.Endpoint.Host != "example.com" || cfg.Endpoint.Path != "/api" {
+		t.Errorf("Expected the endpoint to be parsed, got %v", cfg.Endpoint)
+	}
+	if !cfg.Host.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Expected the host to be parsed, got %v", cfg.Host)
+	}
+	if !cfg.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected the time to be parsed, got %v", cfg.Since)
+	}
+	if cfg.Level != 1 || cfg.Fallback == nil || *cfg.Fallback != 0 {
+		t.Errorf("Expected the levels to be converted, got %v, %v", cfg.Level, cfg.Fallback)
+	}
+
+	for _, input := range []string{"HOST=not-an-ip", "LEVEL=verbose", "ENDPOINT=:bad"} {
+		var cfg struct {
+			Host     net.IP   `env:"HOST" default:"127.0.0.1"`
+			Level    logLevel `env:"LEVEL" default:"info"`
+			Endpoint url.URL  `env:"ENDPOINT" default:"/"`
+		}
+		if err := Decode(strings.NewReader(input), &cfg); err == nil {
+			t.Errorf("Expected %q to fail", input)
+		}
+	}
+
+	RegisterConverter(reflect.TypeOf(logLevel(0)), func(raw string) (interface{}, error) {
+		return raw, nil
+	})
+	var wrong struct {
+		Level logLevel `env:"LEVEL"`
+	}
+	if err := Decode(strings.NewReader("LEVEL=info"), &wrong); err == nil || !strings.Contains(err.Error(), "returned a string") {
+		t.Errorf("Expected a converter returning the wrong type to fail, got %v", err)
+	}
+}
+
+func TestDecodeSlices(t *testing.T) {
+	type config struct {
+		Hosts   []string `env:"HOSTS"`
+		Paths   []string `env:"PATHS" delim:":"`
+		Servers []string `env:"SERVERS" expand:"indexed"`
+		Zones   []string `env:"ZONES" expand:"indexed" delim:";"`
+	}
+
+	input := "HOSTS=a, b,c\nPATHS=/bin:/usr/bin\nSERVERS_0=x,y\nSERVERS_1=z\nSERVERS_3=skipped\nZONES=eu;us"
+	var cfg config
+	if err := Decode(strings.NewReader(input), &cfg); err != nil {
+		t.Fatalf("Expected decode to succeed, got %v", err)
+	}
+	expected := config{
+		Hosts:   []string{"a", "b", "c"},
+		Paths:   []string{"/bin", "/usr/bin"},
+		Servers: []string{"x,y", "z"},
+		Zones:   []string{"eu", "us"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+
+	var wrongType struct {
+		Port int `env:"PORT" expand:"indexed"`
+	}
+	if err := Decode(strings.NewReader("PORT_0=1"), &wrongType); err == nil {
+		t.Error("Expected indexed keys to require a slice")
+	}
+	var unknown struct {
+		Hosts []string `env:"HOSTS" expand:"json"`
+	}
+	if err := Decode(strings.NewReader("HOSTS=a"), &unknown); err == nil || !strings.Contains(err.Error(), "json") {
+		t.Errorf("Expected an unknown strategy to fail, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package godotenv
+
+import (
+	"os"
+	"sort"
+)
+
+// Diff compares the env file(s) (with the same defaults as Load) against the
+// current process environment, for drift detection.
+//
+// Each returned map goes from a key declared in the files to its value there:
+//   - added holds the keys that are not set in the environment
+//   - changed holds the keys set to a different, non-empty, value
+//   - missing holds the keys set to an empty value while the files declare one
+func Diff(filenames ...string) (added, changed, missing map[string]string, err error) {
+	envMap, err := Read(true, filenames...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	added = make(map[string]string)
+	changed = make(map[string]string)
+	missing = make(map[string]string)
+	for key, value := range envMap {
+		current, ok := os.LookupEnv(key)
+		switch {
+		case !ok:
+			added[key] = value
+		case current == value:
+		case current == "":
+			missing[key] = value
+		default:
+			changed[key] = value
+		}
+	}
+	return added, changed, missing, nil
+}
+
+// Merge returns the keys of base with the values of override applied over them,
+// leaving both maps untouched.
+//
+// changed lists, sorted, the keys override adds to base or sets to a different
+// value, so that what each layer of a config contributes can be reported:
+//
+//	merged, changed := godotenv.Merge(defaults, local)
+//	log.Printf("local overrides %v", changed)
+func Merge(base, override map[string]string) (merged map[string]string, changed []string) {
+	merged = make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		if current, ok := base[key]; !ok || current != value {
+			changed = append(changed, key)
+		}
+		merged[key] = value
+	}
+	sort.Strings(changed)
+	return merged, changed
+}
@@ -0,0 +1,59 @@
+package godotenv
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "1")
+	os.Setenv("OPTION_B", "changed")
+	os.Setenv("OPTION_C", "")
+	os.Setenv("OPTION_F", "")
+	os.Setenv("UNRELATED", "value")
+
+	added, changed, missing, err := Diff("fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Error diffing: %v", err)
+	}
+
+	expectedAdded := map[string]string{"OPTION_D": "4", "OPTION_E": "5", "OPTION_G": "", "OPTION_H": "1 2"}
+	if !reflect.DeepEqual(added, expectedAdded) {
+		t.Errorf("Expected added %v, got %v", expectedAdded, added)
+	}
+	expectedChanged := map[string]string{"OPTION_B": "2"}
+	if !reflect.DeepEqual(changed, expectedChanged) {
+		t.Errorf("Expected changed %v, got %v", expectedChanged, changed)
+	}
+	expectedMissing := map[string]string{"OPTION_C": "3"}
+	if !reflect.DeepEqual(missing, expectedMissing) {
+		t.Errorf("Expected missing %v, got %v", expectedMissing, missing)
+	}
+
+	if _, _, _, err := Diff("somefilethatwillneverexistever.env"); err == nil {
+		t.Error("File wasn't found but Diff didn't return an error")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := map[string]string{"HOST": "localhost", "PORT": "8080", "DEBUG": ""}
+	override := map[string]string{"PORT": "9090", "DEBUG": "", "TOKEN": "x"}
+
+	merged, changed := Merge(base, override)
+	expected := map[string]string{"HOST": "localhost", "PORT": "9090", "DEBUG": "", "TOKEN": "x"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected merged %v, got %v", expected, merged)
+	}
+	if expectedChanged := []string{"PORT", "TOKEN"}; !reflect.DeepEqual(changed, expectedChanged) {
+		t.Errorf("Expected changed %v, got %v", expectedChanged, changed)
+	}
+	if base["PORT"] != "8080" || len(base) != 3 {
+		t.Errorf("Expected base to be left untouched, got %v", base)
+	}
+
+	if merged, changed := Merge(nil, nil); len(merged) != 0 || changed != nil {
+		t.Errorf("Expected nothing to merge, got %v, %v", merged, changed)
+	}
+}
@@ -0,0 +1,70 @@
+package godotenv
+
+import (
+	"strings"
+)
+
+// GenerateDocs renders the keys declared by the env file(s) (with the same
+// defaults as Load) as a Markdown table of their type, default and description,
+// taken from the @type, @default and @desc annotations of the comments above
+// them, for the files to be the reference of the configuration they hold:
+//
+//	# @type:int @default:8080 @desc:server port
+//	PORT=8080
+//
+// Keys are listed in the order they are first declared, the annotations of a
+// later declaration filling those an earlier one lacks. Keys without annotations
+// are listed with empty cells. The keys of the files imported with @import are
+// left out, to be documented along with the files declaring them.
+func GenerateDocs(filenames ...string) (string, error) {
+	open := openFrom("./")
+	var keys []string
+	annotations := make(map[string]map[string]string)
+
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return "", err
+	}
+	for _, filename := range filenames {
+		src, err := readSource(open, filename)
+		if err != nil {
+			return "", err
+		}
+		opts := parseOptions{importFile: func(string) ([]Pair, error) { return nil, nil }}
+		entries, err := parseEntries(src, opts)
+		if err != nil {
+			return "", &FileError{Filename: filename, Err: err}
+		}
+
+		for _, entry := range entries {
+			known, ok := annotations[entry.Key]
+			if !ok {
+				keys = append(keys, entry.Key)
+				known = make(map[string]string)
+				annotations[entry.Key] = known
+			}
+			for name, value := range entry.Annotations {
+				if _, ok := known[name]; !ok {
+					known[name] = value
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, key := range keys {
+		a := annotations[key]
+		b.WriteString("| `" + key + "` | " + markdownCell(a["type"]) + " | " +
+			markdownCell(a["default"]) + " | " + markdownCell(a["desc"]) + " |\n")
+	}
+	return b.String(), nil
+}
+
+// markdownCellEscaper escapes the characters that would break a Markdown table cell.
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
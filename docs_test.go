@@ -0,0 +1,50 @@
+package godotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.env": "# HTTP server\n" +
+			"# @type:int @default:8080 @desc:server port\n" +
+			"PORT=8080\n" +
+			"\n" +
+			"# @desc:ignored, separated by a blank line\n" +
+			"\n" +
+			"# a regular comment, contact admin@example.com\n" +
+			"# @type:string\n" +
+			"# @desc:log level, debug|info\n" +
+			"export LOG_LEVEL=info\n" +
+			"@import common.env\n" +
+			"NAME=godotenv\n",
+		"local.env":  "# @desc:overridden, but PORT already has one\n# @default:9090\nPORT=9090\n# @desc:the name\nNAME=local\n",
+		"common.env": "# @desc:imported\nCOMMON=1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chdir(t, dir)
+
+	docs, err := GenerateDocs("base.env", "local.env")
+	if err != nil {
+		t.Fatalf("Error generating docs: %v", err)
+	}
+	expected := "| Key | Type | Default | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `PORT` | int | 8080 | server port |\n" +
+		"| `LOG_LEVEL` | string |  | log level, debug\\|info |\n" +
+		"| `NAME` |  |  | the name |\n"
+	if docs != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, docs)
+	}
+
+	if _, err := GenerateDocs("missing.env"); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
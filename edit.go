@@ -0,0 +1,195 @@
+package godotenv
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Set updates the value of key in the env file filename, leaving the rest of the
+// file, comments, blank lines and order included, as it is.
+//
+// Every declaration of key has its value replaced, while the export keyword and
+// inline comments around it are kept. A key that isn't declared yet is appended
+// to the file, which is created if it doesn't exist.
+//
+// The new value keeps the quoting of the one it replaces, unquoted, single quoted
+// or otherwise, so that edits stay minimal. Values that quoting can't hold, such
+// as one with a space replacing an unquoted value, and the appended keys are
+// serialized as Write would, tweaked by the given options.
+func Set(filename, key, value string, opts ...MarshalOption) error {
+	src, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	o := newMarshalOptions(opts)
+	edited, err := setValue(src, key, value, o)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := w.Write(edited)
+		return err
+	})
+}
+
+// addedMarker introduces the keys Update appends to a file.
+const addedMarker = "# added by godotenv"
+
+// Update is like Set for each of the keys of changes, leaving the keys it
+// doesn't hold, comments and blank lines exactly as they are in filename.
+//
+// Keys that aren't declared yet are appended in sorted order at the end of the
+// file, under a "# added by godotenv" comment, which is written once.
+func Update(filename string, changes map[string]string, opts ...MarshalOption) error {
+	src, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	o := newMarshalOptions(opts)
+	var added []string
+	for _, key := range keys {
+		spans, err := valueSpans(src, key)
+		if err != nil {
+			return err
+		}
+		if len(spans) == 0 {
+			added = append(added, key)
+			continue
+		}
+		src = replaceValues(src, spans, changes[key], o)
+	}
+
+	if len(added) > 0 {
+		if !bytes.HasPrefix(src, []byte(addedMarker+"\n")) && !bytes.Contains(src, []byte("\n"+addedMarker+"\n")) {
+			src = appendLine(src, addedMarker)
+		}
+		for _, key := range added {
+			src = appendLine(src, marshalLine(key, changes[key], o))
+		}
+	}
+
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := w.Write(src)
+		return err
+	})
+}
+
+// setValue returns src with the values of key replaced by value, or with key
+// appended if it isn't declared.
+func setValue(src []byte, key, value string, opts marshalOptions) ([]byte, error) {
+	spans, err := valueSpans(src, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(spans) == 0 {
+		return appendLine(src, marshalLine(key, value, opts)), nil
+	}
+	return replaceValues(src, spans, value, opts), nil
+}
+
+// appendLine returns src with line appended to it, on a line of its own.
+func appendLine(src []byte, line string) []byte {
+	out := src
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return append(out, line+"\n"...)
+}
+
+// replaceValues returns src with the values at spans replaced by value.
+func replaceValues(src []byte, spans []valueSpan, value string, opts marshalOptions) []byte {
+	var out bytes.Buffer
+	last := 0
+	for _, span := range spans {
+		out.Write(src[last:span.start])
+		out.WriteString(marshalValueAs(value, span.quote, opts))
+		last = span.end
+	}
+	out.Write(src[last:])
+	return out.Bytes()
+}
+
+// marshalValueAs returns value quoted as quote when it can be read back as is,
+// and as marshalValue would otherwise.
+func marshalValueAs(value string, quote QuoteStyle, opts marshalOptions) string {
+	switch quote {
+	case Unquoted:
+		// an empty value would let an inline comment be read as the value
+		if value != "" && !needsQuoting(value) {
+			return value
+		}
+	case SingleQuoted:
+		// a trailing backslash would keep the closing quote from ending the value
+		if !strings.ContainsAny(value, "'\r") && !strings.HasSuffix(value, `\`) {
+			return "'" + value + "'"
+		}
+	case DoubleQuoted:
+		return `"` + opts.escape(value) + `"`
+	case TripleQuoted:
+		if blockQuotable(value) {
+			return `"""` + "\n" + value + `"""`
+		}
+	case BacktickQuoted:
+		if !strings.ContainsAny(value, "`\r") {
+			return "`\n" + value + "`"
+		}
+	}
+	return marshalValue(value, opts)
+}
+
+// valueSpan locates a value in an env file, quotes included.
+type valueSpan struct {
+	start, end int
+	quote      QuoteStyle
+}
+
+// valueSpans returns the values declared for key in src, inline comments
+// excluded.
+func valueSpans(src []byte, key string) ([]valueSpan, error) {
+	statements, err := scanStatements(src, parseOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var spans []valueSpan
+	for _, statement := range statements {
+		if statement.key == key {
+			raw := src[statement.valueStart:statement.end]
+			spans = append(spans, valueSpan{
+				start: statement.valueStart,
+				end:   statement.valueStart + valueLength(raw),
+				quote: quoteStyle(src[statement.start:], parseOptions{}),
+			})
+		}
+	}
+	return spans, nil
+}
+
+// valueLength returns the length of the value at the start of raw, an unquoted
+// value being cut at its inline comment and trailing whitespace.
+func valueLength(raw []byte) int {
+	if hasValueDelimiter(raw) {
+		return len(raw)
+	}
+
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == charComment && isSpace(rune(raw[i-1])) {
+			raw = raw[:i]
+			break
+		}
+	}
+	return len(bytes.TrimRightFunc(raw, isSpace))
+}
@@ -0,0 +1,239 @@
+package godotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	original := "# database settings\n" +
+		"export DB_HOST=localhost # overridden in production\n" +
+		"DB_PORT=\"5432\"\n" +
+		"\n" +
+		"# cache settings\n" +
+		"CACHE_URL: redis://localhost\n" +
+		"DB_HOST=127.0.0.1"
+
+	cases := map[string]struct {
+		key      string
+		value    string
+		opts     []MarshalOption
+		expected string
+	}{
+		"every declaration of a key": {
+			key:   "DB_HOST",
+			value: "db.internal",
+			expected: "# database settings\n" +
+				"export DB_HOST=db.internal # overridden in production\n" +
+				"DB_PORT=\"5432\"\n" +
+				"\n" +
+				"# cache settings\n" +
+				"CACHE_URL: redis://localhost\n" +
+				"DB_HOST=db.internal",
+		},
+		"quoted value": {
+			key:   "DB_PORT",
+			value: "6543",
+			expected: "# database settings\n" +
+				"export DB_HOST=localhost # overridden in production\n" +
+				"DB_PORT=\"6543\"\n" +
+				"\n" +
+				"# cache settings\n" +
+				"CACHE_URL: redis://localhost\n" +
+				"DB_HOST=127.0.0.1",
+		},
+		"yaml style value": {
+			key:   "CACHE_URL",
+			value: "redis://cache:6379",
+			opts:  []MarshalOption{MinimalQuoting()},
+			expected: "# database settings\n" +
+				"export DB_HOST=localhost # overridden in production\n" +
+				"DB_PORT=\"5432\"\n" +
+				"\n" +
+				"# cache settings\n" +
+				"CACHE_URL: redis://cache:6379\n" +
+				"DB_HOST=127.0.0.1",
+		},
+		"new key": {
+			key:   "DEBUG",
+			value: "true",
+			expected: "# database settings\n" +
+				"export DB_HOST=localhost # overridden in production\n" +
+				"DB_PORT=\"5432\"\n" +
+				"\n" +
+				"# cache settings\n" +
+				"CACHE_URL: redis://localhost\n" +
+				"DB_HOST=127.0.0.1\n" +
+				"DEBUG=\"true\"\n",
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(filename, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Set(filename, c.key, c.value, c.opts...); err != nil {
+				t.Fatalf("Expected %s to be set, got %v", c.key, err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, string(content))
+			}
+		})
+	}
+}
+
+func TestSetCreatesFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+
+	if err := Set(filename, "NAME", "godotenv"); err != nil {
+		t.Fatalf("Expected NAME to be set, got %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "NAME=\"godotenv\"\n"; string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestSetMalformedFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	original := "FOO=\"unterminated\n"
+	if err := os.WriteFile(filename, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(filename, "FOO", "bar"); err == nil {
+		t.Error("Expected a malformed file to fail to be edited")
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != original {
+		t.Errorf("Expected the malformed file to be left untouched, got %q", string(content))
+	}
+}
+
+func TestSetBOMFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte("\ufeffFOO=bar\r\nBAZ=qux\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(filename, "FOO", "updated"); err != nil {
+		t.Fatalf("Expected FOO to be set, got %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\ufeffFOO=updated\r\nBAZ=qux\r\n"; string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	original := "# settings\n" +
+		"export DB_HOST=localhost # overridden in production\n" +
+		"DB_PORT='5432'\n" +
+		"UNTOUCHED = \"kept as is\""
+	if err := os.WriteFile(filename, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string]string{"DB_PORT": "6543", "DEBUG": "true", "APP_NAME": "godotenv"}
+	if err := Update(filename, changes); err != nil {
+		t.Fatalf("Expected the file to be updated, got %v", err)
+	}
+	if err := Update(filename, map[string]string{"DB_HOST": "db.internal", "LOG_LEVEL": "info"}); err != nil {
+		t.Fatalf("Expected the file to be updated, got %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# settings\n" +
+		"export DB_HOST=db.internal # overridden in production\n" +
+		"DB_PORT='6543'\n" +
+		"UNTOUCHED = \"kept as is\"\n" +
+		"# added by godotenv\n" +
+		"APP_NAME=\"godotenv\"\n" +
+		"DEBUG=\"true\"\n" +
+		"LOG_LEVEL=\"info\"\n"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestSetKeepsQuoting(t *testing.T) {
+	original := "UNQUOTED=a\nCOMMENTED=a # note\nSINGLE='b'\nDOUBLE=\"c\"\nBLOCK=\"\"\"\nd\n\"\"\"\nBACKTICK=`\ne`\nLAST=1\n"
+	cases := map[string]struct {
+		key, value, expected string
+	}{
+		"unquoted":                       {"UNQUOTED", "x", "UNQUOTED=x\n"},
+		"unquoted with space":            {"UNQUOTED", "x y", "UNQUOTED=\"x y\"\n"},
+		"unquoted empty":                 {"COMMENTED", "", "COMMENTED=\"\" # note\n"},
+		"single":                         {"SINGLE", "$x", "SINGLE='$x'\n"},
+		"single with quote":              {"SINGLE", "it's", "SINGLE=\"it's\"\n"},
+		"single with trailing backslash": {"SINGLE", `path\`, `SINGLE="path\\"` + "\n"},
+		"double":                         {"DOUBLE", "1", "DOUBLE=\"1\"\n"},
+		"block":                          {"BLOCK", "x\ny\n", "BLOCK=\"\"\"\nx\ny\n\"\"\"\n"},
+		"block ending in quote":          {"BLOCK", "x\n\"", `BLOCK="x\n\""` + "\n"},
+		"backtick":                       {"BACKTICK", "x\ny", "BACKTICK=`\nx\ny`\n"},
+	}
+	setters := map[string]func(filename, key, value string) error{
+		"Set": func(filename, key, value string) error {
+			return Set(filename, key, value)
+		},
+		"Update": func(filename, key, value string) error {
+			return Update(filename, map[string]string{key: value})
+		},
+	}
+
+	for name, c := range cases {
+		for setterName, set := range setters {
+			c, set := c, set
+			t.Run(setterName+" "+name, func(t *testing.T) {
+				filename := filepath.Join(t.TempDir(), ".env")
+				if err := os.WriteFile(filename, []byte(original), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := set(filename, c.key, c.value); err != nil {
+					t.Fatalf("Expected %s to be set, got %v", c.key, err)
+				}
+
+				content, err := os.ReadFile(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(content), c.expected) {
+					t.Errorf("Expected %q to hold %q", string(content), c.expected)
+				}
+				envMap, err := Unmarshal(string(content))
+				if err != nil || envMap[c.key] != c.value {
+					t.Errorf("Expected %s to be read back as %q, got %q, %v", c.key, c.value, envMap[c.key], err)
+				}
+				if envMap["LAST"] != "1" {
+					t.Errorf("Expected the keys after %s to be kept, got %v", c.key, envMap)
+				}
+			})
+		}
+	}
+}
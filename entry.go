@@ -0,0 +1,136 @@
+package godotenv
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// QuoteStyle is the way a value is quoted in an env file.
+type QuoteStyle int
+
+const (
+	// Unquoted values are written as they are, such as KEY=value.
+	Unquoted QuoteStyle = iota
+	// SingleQuoted values are taken literally, such as KEY='value'.
+	SingleQuoted
+	// DoubleQuoted values have their escapes and variables expanded, such as KEY="value".
+	DoubleQuoted
+	// TripleQuoted values are multiline values enclosed in """.
+	TripleQuoted
+	// BacktickQuoted values are multiline values enclosed in backticks.
+	BacktickQuoted
+)
+
+// Entry is an assignment read from an env file, along with the way its value is
+// quoted in the file.
+type Entry struct {
+	Key   string
+	Value string
+	Quote QuoteStyle
+	// Annotations holds the @name:value annotations of the comment lines right
+	// above the assignment, such as type, default and desc for
+	//
+	//	# @type:int @default:8080 @desc:server port
+	//	PORT=8080
+	//
+	// It is nil when there are none.
+	Annotations map[string]string
+}
+
+// ParseDetailed reads an env file from io.Reader, returning its assignments in
+// file order along with how their values are quoted, for tools that rewrite env
+// files and want to keep the quoting of their authors.
+//
+// Unlike ParseOrdered, a key declared more than once is returned for each of its
+// declarations.
+func ParseDetailed(r io.Reader, opts ...ParseOption) ([]Entry, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+
+	return parseEntries(normalizeSource(buf.Bytes()), newParseOptions(opts))
+}
+
+// parseEntries parses src, which must already be normalized, into entries.
+func parseEntries(src []byte, opts parseOptions) ([]Entry, error) {
+	var entries []Entry
+	err := parseFunc(src, opts, func(key, value string, statement []byte) error {
+		entry := Entry{Key: key, Value: value, Quote: quoteStyle(statement, opts)}
+		// the comments above an @import line document it rather than its keys
+		if !bytes.HasPrefix(statement, []byte(importDirective)) {
+			entry.Annotations = annotationsAbove(src, statement, opts)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// annotationRegex matches the @name: starting each annotation of a comment.
+var annotationRegex = regexp.MustCompile(`(?:^|\s)@(\w+):`)
+
+// annotationsAbove returns the annotations of the comment lines directly above
+// statement, a subslice of src, or nil if there are none. Each annotation goes
+// up to the next one or the end of its line, and comments without any are
+// ignored.
+func annotationsAbove(src, statement []byte, opts parseOptions) map[string]string {
+	var annotations map[string]string
+	end := bytes.LastIndexByte(src[:len(src)-len(statement)], '\n')
+	for end != -1 {
+		start := bytes.LastIndexByte(src[:end], '\n') + 1
+		line := bytes.TrimFunc(src[start:end], isSpace)
+		if len(line) == 0 || !opts.isComment(rune(line[0])) {
+			break
+		}
+		end = start - 1
+
+		comment := string(bytes.TrimLeftFunc(line[1:], isSpace))
+		matches := annotationRegex.FindAllStringSubmatchIndex(comment, -1)
+		for i, match := range matches {
+			valueEnd := len(comment)
+			if i+1 < len(matches) {
+				valueEnd = matches[i+1][0]
+			}
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			name := comment[match[2]:match[3]]
+			// the lines are read upwards, keep the annotation closest to the key
+			if _, ok := annotations[name]; !ok {
+				annotations[name] = strings.TrimSpace(comment[match[1]:valueEnd])
+			}
+		}
+	}
+	return annotations
+}
+
+// quoteStyle returns how the value of statement, which is known to be valid, is quoted.
+func quoteStyle(statement []byte, opts parseOptions) QuoteStyle {
+	_, value, _ := locateKeyName(statement, opts)
+	switch {
+	case opts.rawQuotes:
+		return Unquoted
+	}
+	switch string(blockDelimiter(value)) {
+	case `"""`:
+		return TripleQuoted
+	case "`":
+		return BacktickQuoted
+	}
+
+	quote, quoted := hasQuotePrefix(value)
+	switch {
+	case !quoted:
+		return Unquoted
+	case quote == prefixSingleQuote:
+		return SingleQuoted
+	default:
+		return DoubleQuoted
+	}
+}
@@ -0,0 +1,64 @@
+package godotenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDetailed(t *testing.T) {
+	input := "PLAIN=value\n" +
+		"export SINGLE='$literal'\n" +
+		"DOUBLE: \"line\\nbreak\"\n" +
+		"TRIPLE=\"\"\"\nmulti\n\"\"\"\n" +
+		"BACKTICK=`\nmulti`\n" +
+		"PLAIN=\"redeclared\" # comment\n"
+
+	entries, err := ParseDetailed(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected input to parse, got %v", err)
+	}
+
+	expected := []Entry{
+		{Key: "PLAIN", Value: "value", Quote: Unquoted},
+		{Key: "SINGLE", Value: "$literal", Quote: SingleQuoted},
+		{Key: "DOUBLE", Value: "line\nbreak", Quote: DoubleQuoted},
+		{Key: "TRIPLE", Value: "multi\n", Quote: TripleQuoted},
+		{Key: "BACKTICK", Value: "multi", Quote: BacktickQuoted},
+		{Key: "PLAIN", Value: "redeclared", Quote: DoubleQuoted},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+func TestParseDetailedError(t *testing.T) {
+	if _, err := ParseDetailed(strings.NewReader("KEY='unterminated")); err == nil {
+		t.Error("Expected an unterminated value to fail")
+	}
+}
+
+func TestParseDetailedAnnotations(t *testing.T) {
+	input := "# @type:int @default:8080 @desc:server port\n" +
+		"PORT=8080\n" +
+		"# just a comment, mail admin@example.com\n" +
+		"HOST=localhost\n" +
+		"# @desc:first\n" +
+		"\n" +
+		"NAME=godotenv\n"
+
+	entries, err := ParseDetailed(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected input to parse, got %v", err)
+	}
+
+	expected := map[string]string{"type": "int", "default": "8080", "desc": "server port"}
+	if !reflect.DeepEqual(entries[0].Annotations, expected) {
+		t.Errorf("Expected %v, got %v", expected, entries[0].Annotations)
+	}
+	for _, entry := range entries[1:] {
+		if entry.Annotations != nil {
+			t.Errorf("Expected %s to have no annotations, got %v", entry.Key, entry.Annotations)
+		}
+	}
+}
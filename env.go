@@ -0,0 +1,121 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+)
+
+// Unload removes from the environment every key declared in the env file(s)
+// (with the same defaults as Load), undoing a previous Load of them.
+//
+// Keys are removed whatever their current value, including keys that were
+// already set before the files were loaded. Use LoadRestorable to only undo
+// what a load actually did.
+func Unload(filenames ...string) error {
+	envMap, err := Read(true, filenames...)
+	if err != nil {
+		return err
+	}
+
+	envMu.Lock()
+	defer envMu.Unlock()
+	for key := range envMap {
+		if err := os.Unsetenv(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadRestorable is like Load, but also returns a function that restores the
+// environment as it was before the call, leaving alone the keys that Load
+// skipped because they were already set.
+//
+//	restore, err := godotenv.LoadRestorable(true, "fixtures/test.env")
+//	defer restore()
+//
+// The restore function is usable even when an error is returned, undoing what
+// was loaded before the error occurred.
+func LoadRestorable(strict bool, filenames ...string) (restore func() error, err error) {
+	result, err := LoadWithResult(strict, filenames...)
+	restore = func() error {
+		envMu.Lock()
+		defer envMu.Unlock()
+		for _, key := range result.Set {
+			if err := os.Unsetenv(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return restore, err
+}
+
+// WithEnv loads the env file(s) (with the same semantics as Load, in strict mode),
+// runs fn and then restores the environment exactly as it was before the call,
+// including any change fn itself made to it.
+//
+// It is meant for tests needing a different configuration per case:
+//
+//	err := godotenv.WithEnv([]string{"fixtures/test.env"}, func() error {
+//		return run()
+//	})
+func WithEnv(filenames []string, fn func() error) (err error) {
+	snapshot := ParseEnviron(os.Environ())
+	defer func() {
+		if restoreErr := restoreEnviron(snapshot); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	if err := Load(true, filenames...); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// restoreEnviron makes the environment hold exactly the variables of snapshot.
+func restoreEnviron(snapshot map[string]string) error {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	for key := range ParseEnviron(os.Environ()) {
+		if _, ok := snapshot[key]; !ok {
+			if err := os.Unsetenv(key); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range snapshot {
+		if current, ok := os.LookupEnv(key); !ok || current != value {
+			if err := os.Setenv(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseEnviron converts an environment in the os.Environ format, such as
+// []string{"A=1", "B=2"}, into a map.
+//
+// Entries are split on their first =, so values can contain = themselves, and
+// entries without one are skipped. This allows snapshotting the environment and
+// comparing it with the content of env files:
+//
+//	before := godotenv.ParseEnviron(os.Environ())
+func ParseEnviron(environ []string) map[string]string {
+	envMap := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if entry == "" {
+			continue
+		}
+		// look past the first character, on Windows some names start with = such as =C:
+		i := strings.IndexByte(entry[1:], '=')
+		if i == -1 {
+			continue
+		}
+		envMap[entry[:i+1]] = entry[i+2:]
+	}
+	return envMap
+}
@@ -0,0 +1,101 @@
+package godotenv
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUnload(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("UNRELATED", "value")
+
+	if err := Load(true, "fixtures/plain.env"); err != nil {
+		t.Fatalf("Error loading file: %v", err)
+	}
+	if err := Unload("fixtures/plain.env"); err != nil {
+		t.Fatalf("Error unloading file: %v", err)
+	}
+
+	if expected := []string{"UNRELATED=value"}; !reflect.DeepEqual(os.Environ(), expected) {
+		t.Errorf("Expected environment %v, got %v", expected, os.Environ())
+	}
+
+	if err := Unload("somefilethatwillneverexistever.env"); err == nil {
+		t.Error("File wasn't found but Unload didn't return an error")
+	}
+}
+
+func TestLoadRestorable(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "preset")
+	os.Setenv("UNRELATED", "value")
+
+	restore, err := LoadRestorable(true, "fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Error loading file: %v", err)
+	}
+	if os.Getenv("OPTION_B") != "2" {
+		t.Fatalf("Expected the file to be loaded, got %v", os.Environ())
+	}
+
+	if err := restore(); err != nil {
+		t.Fatalf("Error restoring environment: %v", err)
+	}
+	expected := []string{"OPTION_A=preset", "UNRELATED=value"}
+	actual := os.Environ()
+	sort.Strings(actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected environment %v, got %v", expected, actual)
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "preset")
+	os.Setenv("UNRELATED", "value")
+
+	err := WithEnv([]string{"fixtures/plain.env"}, func() error {
+		if os.Getenv("OPTION_A") != "preset" || os.Getenv("OPTION_B") != "2" {
+			t.Errorf("Expected the file to be loaded, got %v", os.Environ())
+		}
+		os.Setenv("UNRELATED", "changed")
+		os.Setenv("ADDED", "by fn")
+		os.Unsetenv("OPTION_A")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"OPTION_A=preset", "UNRELATED=value"}
+	actual := os.Environ()
+	sort.Strings(actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected environment %v, got %v", expected, actual)
+	}
+
+	fnErr := errors.New("failed")
+	if err := WithEnv([]string{"fixtures/plain.env"}, func() error { return fnErr }); err != fnErr {
+		t.Errorf("Expected the error of fn to be returned, got %v", err)
+	}
+	if err := WithEnv([]string{"somefilethatwillneverexistever.env"}, func() error { return nil }); err == nil {
+		t.Error("File wasn't found but WithEnv didn't return an error")
+	}
+}
+
+func TestParseEnviron(t *testing.T) {
+	environ := []string{"A=1", "URL=http://host/?a=b", "EMPTY=", "", "INVALID", "=C:=C:\\dir", "A=2"}
+
+	expected := map[string]string{
+		"A":     "2",
+		"URL":   "http://host/?a=b",
+		"EMPTY": "",
+		"=C:":   "C:\\dir",
+	}
+	if actual := ParseEnviron(environ); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,43 @@
+package godotenv
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// BindFlags sets the flags of fs that weren't given on the command line from the
+// environment, such as after a Load, each flag being read from the variable
+// named after it as by Normalize, so that -db-url is read from DB_URL:
+//
+//	dbURL := flag.String("db-url", "", "database URL")
+//	flag.Parse()
+//	godotenv.Load(false)
+//	if err := godotenv.BindFlags(flag.CommandLine); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Flags whose variable isn't set keep their default, and it errors on the first
+// variable the flag doesn't accept.
+func BindFlags(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+		key := Normalize(f.Name)
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("flag -%s from %s: %s", f.Name, key, redactMessage(key, value, setErr.Error()))
+		}
+	})
+	return err
+}
@@ -0,0 +1,42 @@
+package godotenv
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBindFlags(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DB_URL", "postgres://localhost")
+	os.Setenv("WORKERS", "4")
+	os.Setenv("PORT", "9090")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	dbURL := fs.String("db-url", "", "")
+	workers := fs.Int("workers", 1, "")
+	port := fs.Int("port", 8080, "")
+	timeout := fs.Duration("timeout", time.Second, "")
+	if err := fs.Parse([]string{"-port", "3000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := BindFlags(fs); err != nil {
+		t.Fatalf("Expected the flags to be bound, got %v", err)
+	}
+	if *dbURL != "postgres://localhost" || *workers != 4 {
+		t.Errorf("Expected unset flags to be read from the environment, got %q and %d", *dbURL, *workers)
+	}
+	if *port != 3000 {
+		t.Errorf("Expected flags given on the command line to be kept, got %d", *port)
+	}
+	if *timeout != time.Second {
+		t.Errorf("Expected flags without a variable to keep their default, got %v", *timeout)
+	}
+
+	os.Setenv("TIMEOUT", "soon")
+	if err := BindFlags(fs); err == nil {
+		t.Error("Expected an invalid value to fail")
+	}
+}
@@ -0,0 +1,117 @@
+package godotenv
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetInt returns the value of the environment variable named by key parsed as an int.
+//
+// It errors if the variable isn't set or doesn't hold an integer.
+func GetInt(key string) (int, error) {
+	value, err := lookupEnv(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", key, redactMessage(key, value, err.Error()))
+	}
+	return n, nil
+}
+
+// GetIntDefault is like GetInt, but returns def when the variable can't be read as an int.
+func GetIntDefault(key string, def int) int {
+	if n, err := GetInt(key); err == nil {
+		return n
+	}
+	return def
+}
+
+// GetBool returns the value of the environment variable named by key parsed as a bool.
+//
+// Besides the values accepted by strconv.ParseBool, "yes", "y" and "on" are read
+// as true and "no", "n" and "off" as false, regardless of their case.
+func GetBool(key string) (bool, error) {
+	value, err := lookupEnv(key)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", key, redactMessage(key, value, err.Error()))
+	}
+	return b, nil
+}
+
+// GetBoolDefault is like GetBool, but returns def when the variable can't be read as a bool.
+func GetBoolDefault(key string, def bool) bool {
+	if b, err := GetBool(key); err == nil {
+		return b
+	}
+	return def
+}
+
+// GetDuration returns the value of the environment variable named by key parsed
+// as a time.Duration, such as "300ms" or "1h30m".
+func GetDuration(key string) (time.Duration, error) {
+	value, err := lookupEnv(key)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", key, redactMessage(key, value, err.Error()))
+	}
+	return d, nil
+}
+
+// GetDurationDefault is like GetDuration, but returns def when the variable can't be read as a duration.
+func GetDurationDefault(key string, def time.Duration) time.Duration {
+	if d, err := GetDuration(key); err == nil {
+		return d
+	}
+	return def
+}
+
+// GetFloat returns the value of the environment variable named by key parsed as a float64.
+func GetFloat(key string) (float64, error) {
+	value, err := lookupEnv(key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", key, redactMessage(key, value, err.Error()))
+	}
+	return f, nil
+}
+
+// GetFloatDefault is like GetFloat, but returns def when the variable can't be read as a float64.
+func GetFloatDefault(key string, def float64) float64 {
+	if f, err := GetFloat(key); err == nil {
+		return f
+	}
+	return def
+}
+
+func lookupEnv(key string) (string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	return value, nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
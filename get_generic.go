@@ -0,0 +1,44 @@
+package godotenv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// GetFrom returns the value of key in m, as returned by Read, parsed as a T,
+// which must be one of string, int, bool, float64 or time.Duration:
+//
+//	envMap, err := godotenv.Read()
+//	port, err := godotenv.GetFrom[int](envMap, "PORT")
+//
+// Values are parsed as by GetInt, GetBool, GetFloat and GetDuration, and it
+// errors if key isn't in m or doesn't hold a T.
+func GetFrom[T any](m map[string]string, key string) (T, error) {
+	var zero T
+	value, ok := m[key]
+	if !ok {
+		return zero, fmt.Errorf("%s is not set", key)
+	}
+
+	var parsed any
+	var err error
+	switch any(zero).(type) {
+	case string:
+		parsed = value
+	case int:
+		parsed, err = strconv.Atoi(value)
+	case bool:
+		parsed, err = parseBool(value)
+	case float64:
+		parsed, err = strconv.ParseFloat(value, 64)
+	case time.Duration:
+		parsed, err = time.ParseDuration(value)
+	default:
+		return zero, fmt.Errorf("%s: unsupported type %T", key, zero)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("%s: %s", key, redactMessage(key, value, err.Error()))
+	}
+	return parsed.(T), nil
+}
@@ -0,0 +1,43 @@
+package godotenv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetFrom(t *testing.T) {
+	envMap := map[string]string{
+		"NAME":    "godotenv",
+		"PORT":    "8080",
+		"DEBUG":   "yes",
+		"RATIO":   "0.5",
+		"TIMEOUT": "1m30s",
+		"INVALID": "abc",
+	}
+
+	if name, err := GetFrom[string](envMap, "NAME"); err != nil || name != "godotenv" {
+		t.Errorf("Expected NAME to be godotenv, got %q, %v", name, err)
+	}
+	if port, err := GetFrom[int](envMap, "PORT"); err != nil || port != 8080 {
+		t.Errorf("Expected PORT to be 8080, got %d, %v", port, err)
+	}
+	if debug, err := GetFrom[bool](envMap, "DEBUG"); err != nil || !debug {
+		t.Errorf("Expected DEBUG to be true, got %t, %v", debug, err)
+	}
+	if ratio, err := GetFrom[float64](envMap, "RATIO"); err != nil || ratio != 0.5 {
+		t.Errorf("Expected RATIO to be 0.5, got %v, %v", ratio, err)
+	}
+	if timeout, err := GetFrom[time.Duration](envMap, "TIMEOUT"); err != nil || timeout != 90*time.Second {
+		t.Errorf("Expected TIMEOUT to be 1m30s, got %v, %v", timeout, err)
+	}
+
+	if _, err := GetFrom[int](envMap, "INVALID"); err == nil {
+		t.Error("Expected INVALID not to parse as an int")
+	}
+	if _, err := GetFrom[string](envMap, "MISSING"); err == nil || err.Error() != "MISSING is not set" {
+		t.Errorf("Expected MISSING not to be set, got %v", err)
+	}
+	if _, err := GetFrom[[]string](envMap, "NAME"); err == nil {
+		t.Error("Expected unsupported types to fail")
+	}
+}
@@ -0,0 +1,65 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGetters(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	os.Setenv("DEBUG", "Yes")
+	os.Setenv("VERBOSE", "off")
+	os.Setenv("TIMEOUT", "1m30s")
+	os.Setenv("RATIO", "0.25")
+	os.Setenv("INVALID", "nope")
+
+	if n, err := GetInt("PORT"); err != nil || n != 8080 {
+		t.Errorf("Expected PORT to be 8080, got %v (%v)", n, err)
+	}
+	if b, err := GetBool("DEBUG"); err != nil || !b {
+		t.Errorf("Expected DEBUG to be true, got %v (%v)", b, err)
+	}
+	if b, err := GetBool("VERBOSE"); err != nil || b {
+		t.Errorf("Expected VERBOSE to be false, got %v (%v)", b, err)
+	}
+	if d, err := GetDuration("TIMEOUT"); err != nil || d != 90*time.Second {
+		t.Errorf("Expected TIMEOUT to be 1m30s, got %v (%v)", d, err)
+	}
+	if f, err := GetFloat("RATIO"); err != nil || f != 0.25 {
+		t.Errorf("Expected RATIO to be 0.25, got %v (%v)", f, err)
+	}
+
+	if _, err := GetInt("INVALID"); err == nil {
+		t.Error("Expected GetInt to fail on a non integer value")
+	}
+	if _, err := GetBool("INVALID"); err == nil {
+		t.Error("Expected GetBool to fail on a non boolean value")
+	}
+	if _, err := GetInt("MISSING"); err == nil {
+		t.Error("Expected GetInt to fail on a missing key")
+	}
+}
+
+func TestGetterDefaults(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	os.Setenv("INVALID", "nope")
+
+	if n := GetIntDefault("PORT", 1); n != 8080 {
+		t.Errorf("Expected PORT to be 8080, got %v", n)
+	}
+	if n := GetIntDefault("INVALID", 1); n != 1 {
+		t.Errorf("Expected INVALID to fall back to 1, got %v", n)
+	}
+	if b := GetBoolDefault("MISSING", true); !b {
+		t.Errorf("Expected MISSING to fall back to true, got %v", b)
+	}
+	if d := GetDurationDefault("MISSING", time.Second); d != time.Second {
+		t.Errorf("Expected MISSING to fall back to 1s, got %v", d)
+	}
+	if f := GetFloatDefault("INVALID", 1.5); f != 1.5 {
+		t.Errorf("Expected INVALID to fall back to 1.5, got %v", f)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/AzraelSec/godotenv
 
-go 1.12
+go 1.18
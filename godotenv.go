@@ -14,249 +14,1828 @@
 package godotenv
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/rand"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 const doubleQuoteSpecialChars = "\\\n\r\"!$`"
 
+// unquotedSafeChars are the punctuation characters that don't require a value to be quoted.
+const unquotedSafeChars = "_-.,:/@+%=~"
+
 var noEnvFileLoadedErr = errors.New("no env file loaded")
 
-// Parse reads an env file from io.Reader, returning a map of keys and values.
-func Parse(r io.Reader) (map[string]string, error) {
-	var buf bytes.Buffer
-	_, err := io.Copy(&buf, r)
-	if err != nil {
-		return nil, err
+// ErrFileNotFound is matched by errors.Is when an env file doesn't exist, telling
+// it apart from a *ParseError returned for a malformed file:
+//
+//	err := godotenv.Load(true, ".env", ".env.local")
+//	if errors.Is(err, godotenv.ErrFileNotFound) {
+//		// ...
+//	}
+//
+// The errors matching it are still *fs.PathError, and also match fs.ErrNotExist.
+var ErrFileNotFound = errors.New("env file not found")
+
+// ErrOutsideRoot is matched by errors.Is when LoadWithin is given an env file that
+// resolves outside of its directory.
+var ErrOutsideRoot = errors.New("env file is outside of the root directory")
+
+// fileNotFoundError wraps the cause of the *fs.PathError returned when an env
+// file doesn't exist.
+type fileNotFoundError struct {
+	err error
+}
+
+func (e *fileNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fileNotFoundError) Is(target error) bool {
+	return target == ErrFileNotFound
+}
+
+func (e *fileNotFoundError) Unwrap() error {
+	return e.err
+}
+
+// Pair is a single key/value assignment read from, or destined for, an env file.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Parser parses env files with the options it was created with, for applications
+// parsing many files the same way. A Parser is safe for concurrent use.
+type Parser struct {
+	opts parseOptions
+}
+
+// NewParser returns a Parser applying the given options to every file it parses:
+//
+//	p := godotenv.NewParser(godotenv.DisableExpansion(), godotenv.StrictKeys())
+//	envMap, err := p.Parse(reader)
+func NewParser(opts ...ParseOption) *Parser {
+	return &Parser{opts: newParseOptions(opts)}
+}
+
+// defaultParser is used by the package level functions called without options.
+var defaultParser = NewParser()
+
+// Parse reads an env file from io.Reader, returning a map of keys and values.
+func (p *Parser) Parse(r io.Reader) (map[string]string, error) {
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ParseBytes(buf.Bytes())
+}
+
+// ParseBytes parses an env file held in src, returning a map of keys and values.
+// src is read in place and never modified.
+func (p *Parser) ParseBytes(src []byte) (map[string]string, error) {
+	return unmarshalBytes(src, p.opts)
+}
+
+// Unmarshal reads an env file from a string, returning a map of keys and values.
+func (p *Parser) Unmarshal(str string) (map[string]string, error) {
+	return p.ParseBytes([]byte(str))
+}
+
+// ParseOrdered reads an env file from io.Reader, returning its key/value pairs
+// in the order they are declared, as the ParseOrdered function does.
+func (p *Parser) ParseOrdered(r io.Reader) ([]Pair, error) {
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBytes(buf.Bytes(), p.opts)
+}
+
+// Parse reads an env file from io.Reader, returning a map of keys and values.
+//
+// It is a streaming wrapper around ParseBytes, which should be preferred when the
+// content is already held in memory as it saves copying it into a buffer.
+func Parse(r io.Reader) (map[string]string, error) {
+	return defaultParser.Parse(r)
+}
+
+// ParseWithOptions reads an env file from io.Reader like Parse, with its behaviour
+// tweaked by the given options:
+//
+//	envMap, err := godotenv.ParseWithOptions(reader, godotenv.DisableExpansion())
+//
+// Use a Parser to parse several files with the same options.
+func ParseWithOptions(r io.Reader, opts ...ParseOption) (map[string]string, error) {
+	return NewParser(opts...).Parse(r)
+}
+
+// ParseContext is like ParseWithOptions, but stops reading r once ctx is done,
+// returning the error of ctx, so that a stalled network reader can't block the
+// caller forever:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	envMap, err := godotenv.ParseContext(ctx, resp.Body)
+//
+// A read already blocked when ctx is done keeps going in the background until r
+// returns, the content it reads being discarded. Closing r, as when the request of
+// an HTTP response body is canceled, unblocks it.
+func ParseContext(ctx context.Context, r io.Reader, opts ...ParseOption) (map[string]string, error) {
+	type result struct {
+		src []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, contextReader{ctx: ctx, r: r})
+		done <- result{src: buf.Bytes(), err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return unmarshalBytes(res.src, newParseOptions(opts))
+	}
+}
+
+// contextReader reads from r until ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// ParseOrdered reads an env file from io.Reader, returning its key/value pairs
+// in the order they are declared.
+//
+// A key declared more than once keeps the position of its first declaration
+// and the value of its last one, matching what Parse would return for it.
+func ParseOrdered(r io.Reader, opts ...ParseOption) ([]Pair, error) {
+	return NewParser(opts...).ParseOrdered(r)
+}
+
+// ParseFunc reads an env file from io.Reader and calls fn for each of its
+// assignments in file order, stopping at the first error fn returns, rather than
+// collecting them in a map.
+//
+// A key declared more than once is passed to fn for each of its declarations.
+// Values are passed once expanded, so the values of earlier assignments are still
+// kept around while parsing. Errors only found once the whole file is read, such
+// as cyclic references, are returned after fn was called.
+func ParseFunc(r io.Reader, fn func(key, value string) error, opts ...ParseOption) error {
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, r)
+	if err != nil {
+		return err
+	}
+
+	return parseFunc(normalizeSource(buf.Bytes()), newParseOptions(opts), func(key, value string, _ []byte) error {
+		return fn(key, value)
+	})
+}
+
+// ParseMulti reads env files from each of the readers in order and returns their
+// merged values, the values of later readers replacing those of earlier ones.
+//
+// This allows layering configuration from different sources, such as embedded
+// defaults overridden by a file and then by a remote source. As with Read, each
+// reader is parsed on its own, so variables are only expanded from the reader
+// declaring them.
+func ParseMulti(readers ...io.Reader) (map[string]string, error) {
+	envMap := make(map[string]string)
+	for _, r := range readers {
+		individualEnvMap, err := Parse(r)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range individualEnvMap {
+			envMap[key] = value
+		}
+	}
+	return envMap, nil
+}
+
+// Load will read your env file(s) and load them into ENV for this process.
+//
+// Call this function as close as possible to the start of your program (ideally in main).
+//
+// If you call Load without any args it will default to loading .env in the current path.
+//
+// You can otherwise tell it which files to load (there can be more than one) like:
+//
+//	godotenv.Load("fileone", "filetwo")
+//
+// Filenames can also be glob patterns, see LoadFrom.
+//
+// It's important to note that it WILL NOT OVERRIDE an env variable that already exists - consider the .env file to set dev vars or sensible defaults.
+//
+// Load is safe for concurrent use, a key declared by files loaded concurrently is
+// set by a single one of them.
+func Load(strict bool, filenames ...string) (err error) {
+	return LoadFrom("./", strict, filenames...)
+}
+
+// LoadFrom is like Load, with filenames relative to dir.
+//
+// Filenames can be glob patterns, as accepted by filepath.Match, to load every
+// matching file in sorted order, as with every function reading env files:
+//
+//	godotenv.LoadFrom("./", true, "config/*.env")
+//
+// A pattern matching no file is treated as a missing file.
+func LoadFrom(dir string, strict bool, filenames ...string) (err error) {
+	_, err = loadFiles(openFrom(dir), strict, false, filenames, loadOptions{glob: globFrom(dir)})
+	return
+}
+
+// LoadWithin is like LoadFrom, for filenames that can't be trusted, such as
+// those taken from a config. The files that resolve outside of dir, through ..
+// elements or symlinks, including the ones they @import, aren't read and fail
+// with an *fs.PathError matching ErrOutsideRoot:
+//
+//	err := godotenv.LoadWithin("/etc/myapp", true, cfg.EnvFile)
+//	if errors.Is(err, godotenv.ErrOutsideRoot) {
+//		// ...
+//	}
+func LoadWithin(dir string, strict bool, filenames ...string) (err error) {
+	_, err = loadFiles(openWithin(dir), strict, false, filenames, loadOptions{glob: globFrom(dir)})
+	return
+}
+
+// LoadFromWithOptions is like LoadFrom, with the way values are applied to the
+// environment tweaked by the given options, such as the OnFileLoaded, OnKeySet
+// and OnError hooks:
+//
+//	err := godotenv.LoadFromWithOptions("./", true, nil, godotenv.OnError(func(filename string, err error) {
+//		log.Printf("loading %s: %v", filename, err)
+//	}))
+func LoadFromWithOptions(dir string, strict bool, filenames []string, opts ...LoadOption) (err error) {
+	o := newLoadOptions(opts)
+	o.glob = globFrom(dir)
+	_, err = loadFiles(openFrom(dir), strict, false, filenames, o)
+	return
+}
+
+// FileError reports an env file that failed to be read.
+type FileError struct {
+	Filename string
+	Err      error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Filename, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedFilesError lists the env files skipped by a non-strict read because they
+// failed to be read, while the values of the other files were still returned.
+type SkippedFilesError struct {
+	Files []*FileError
+}
+
+func (e *SkippedFilesError) Error() string {
+	messages := make([]string, len(e.Files))
+	for i, file := range e.Files {
+		messages[i] = "skipped " + file.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of the skipped files, for errors.Is and errors.As.
+func (e *SkippedFilesError) Unwrap() []error {
+	errs := make([]error, len(e.Files))
+	for i, file := range e.Files {
+		errs[i] = file
+	}
+	return errs
+}
+
+// Is reports whether the error of one of the skipped files matches target, as
+// errors.Is only follows Unwrap() []error from Go 1.20.
+func (e *SkippedFilesError) Is(target error) bool {
+	for _, file := range e.Files {
+		if errors.Is(file, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of the skipped files matching target, as errors.As
+// only follows Unwrap() []error from Go 1.20.
+func (e *SkippedFilesError) As(target interface{}) bool {
+	for _, file := range e.Files {
+		if errors.As(file, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Result reports what loading env files did to the process environment.
+type Result struct {
+	// Set lists the keys written into the environment, in file order.
+	Set []string
+	// Skipped lists the keys left untouched because they were already set.
+	//
+	// When several files declare the same key, it is set by the first one
+	// and skipped by the others.
+	Skipped []string
+	// FilesLoaded lists the files that were read successfully.
+	FilesLoaded []string
+	// Overridden lists, in sorted order, the keys forced by the WithOverrides
+	// option once the files were loaded, whether the files declare them or not.
+	Overridden []string
+}
+
+// LoadWithResult is like Load, but also reports which keys were set and which
+// were skipped because they already existed in the environment.
+func LoadWithResult(strict bool, filenames ...string) (result Result, err error) {
+	return loadFiles(openFrom("./"), strict, false, filenames, loadOptions{})
+}
+
+// LoadWithResultOptions is like LoadWithResult, with the way values are applied
+// to the environment tweaked by the given options:
+//
+//	result, err := godotenv.LoadWithResultOptions(true, nil, godotenv.WithOverrides(overrides))
+func LoadWithResultOptions(strict bool, filenames []string, opts ...LoadOption) (Result, error) {
+	return loadFiles(openFrom("./"), strict, false, filenames, newLoadOptions(opts))
+}
+
+// Resolve returns the environment Load, or Overload when overload is true, would
+// leave the process with, without changing it: the variables of the environment
+// with the values of the env file(s) applied to them in order.
+//
+// This allows previewing, or testing, how layered files resolve:
+//
+//	env, err := godotenv.Resolve(true, false, ".env", ".env.local")
+func Resolve(strict, overload bool, filenames ...string) (map[string]string, error) {
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return nil, err
+	}
+	return envFromFiles(openFrom("./"), strict, overload, filenames, ParseEnviron(os.Environ()))
+}
+
+// LoadWithOverrides is like Load, but once the files are loaded, the values of
+// overrides are set into the environment whatever the files and the environment
+// hold, such as to force a few values in a test harness:
+//
+//	err := godotenv.LoadWithOverrides(map[string]string{"DATABASE_URL": testURL}, true)
+//
+// The overrides are only applied when the files load without error. Use the
+// WithOverrides option with LoadWithResultOptions to also get them reported.
+func LoadWithOverrides(overrides map[string]string, strict bool, filenames ...string) error {
+	return LoadWithOptions(strict, filenames, WithOverrides(overrides))
+}
+
+// Change describes what loading an env file would do to a key of the environment.
+type Change struct {
+	Key string
+	// Old is the current value of the key, empty when it isn't set.
+	Old string
+	// New is the value declared in the file.
+	New string
+	// Set reports whether the key would be set, or skipped because it is already set.
+	Set bool
+}
+
+// String renders the change, with the values of secret keys redacted as set by
+// RedactKeys.
+func (c Change) String() string {
+	action := "skip"
+	if c.Set {
+		action = "set"
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", action, c.Key, quoteValue(c.Key, c.Old), quoteValue(c.Key, c.New))
+}
+
+// LoadDryRun reports what Load would do to the environment, without changing it.
+//
+// The plan lists a change for each key of the files, in file order. When several
+// files declare the same key, the change of the first one sets it while those of
+// the others are skipped, with Old holding the value it would have by then.
+func LoadDryRun(strict bool, filenames ...string) (plan []Change, err error) {
+	open := openFrom("./")
+	planned := make(map[string]string)
+	loaded := false
+	if filenames, err = expandGlobs(globFrom("./"), filenames); err != nil {
+		return nil, err
+	}
+
+	for _, filename := range filenames {
+		pairs, err := readPairs(open, filename)
+		if err != nil && strict {
+			return plan, err
+		}
+		if err != nil {
+			continue
+		}
+
+		loaded = true
+		for _, pair := range pairs {
+			current, exists := planned[pair.Key]
+			if !exists {
+				current, exists = os.LookupEnv(pair.Key)
+			}
+			if !exists {
+				planned[pair.Key] = pair.Value
+			}
+			plan = append(plan, Change{Key: pair.Key, Old: current, New: pair.Value, Set: !exists})
+		}
+	}
+
+	if !loaded {
+		return plan, noEnvFileLoadedErr
+	}
+	return plan, nil
+}
+
+// LoadFS will read your env file(s) from fsys and load them into ENV for this process,
+// with the same semantics as Load.
+//
+// This is handy to load env files embedded in the binary:
+//
+//	//go:embed .env
+//	var envFS embed.FS
+//
+//	godotenv.LoadFS(envFS, true)
+func LoadFS(fsys fs.FS, strict bool, filenames ...string) (err error) {
+	_, err = loadFiles(fsys.Open, strict, false, filenames, loadOptions{glob: globFS(fsys)})
+	return
+}
+
+// LoadCascade loads, in order, .env, .env.local, .env.{environment} and
+// .env.{environment}.local from the current path, the way Vite or Next.js do.
+//
+// Each file overrides the values of the files before it, but none of them
+// overrides an env variable that was already set before the call. Files that
+// don't exist are skipped, and when environment is empty only .env and
+// .env.local are considered.
+func LoadCascade(environment string, strict bool) error {
+	open := openFrom("./")
+	var pairs []Pair
+	loaded := false
+
+	for _, filename := range cascadeFilenames(environment) {
+		filePairs, err := readPairs(open, filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil && strict {
+			return err
+		}
+		if err != nil {
+			continue
+		}
+
+		loaded = true
+		pairs = mergePairs(pairs, filePairs)
+	}
+
+	if !loaded {
+		return noEnvFileLoadedErr
+	}
+	applyPairs(pairs, EnvWins, &Result{})
+	return nil
+}
+
+func cascadeFilenames(environment string) []string {
+	filenames := []string{".env", ".env.local"}
+	if environment != "" {
+		filenames = append(filenames, ".env."+environment, ".env."+environment+".local")
+	}
+	return filenames
+}
+
+// LoadWithOptions is like Load, with the way values are applied to the environment
+// tweaked by the given options:
+//
+//	err := godotenv.LoadWithOptions(true, []string{".env"}, godotenv.Filter(godotenv.WithPrefix("APP_")))
+func LoadWithOptions(strict bool, filenames []string, opts ...LoadOption) error {
+	_, err := loadFiles(openFrom("./"), strict, false, filenames, newLoadOptions(opts))
+	return err
+}
+
+// LoadFiltered is like Load, but only the keys for which keep returns true are
+// loaded. The others are neither set nor overridden.
+//
+//	err := godotenv.LoadFiltered(godotenv.WithPrefix("APP_"), true)
+func LoadFiltered(keep func(key string) bool, strict bool, filenames ...string) error {
+	return LoadWithOptions(strict, filenames, Filter(keep))
+}
+
+// WithPrefix returns a predicate, for use with LoadFiltered or Filter, matching the
+// keys that start with prefix.
+func WithPrefix(prefix string) func(key string) bool {
+	return func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}
+}
+
+// Normalize returns key uppercased, with the characters other than letters,
+// digits and _ replaced by _ and a leading digit prefixed by _, so that it is
+// accepted by StrictKeys:
+//
+//	godotenv.Normalize("db.host") // DB_HOST
+func Normalize(key string) string {
+	key = strings.Map(func(c rune) rune {
+		if c == '_' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			return c
+		}
+		if 'a' <= c && c <= 'z' {
+			return c - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+	if key != "" && '0' <= key[0] && key[0] <= '9' {
+		key = "_" + key
+	}
+	return key
+}
+
+// LoadCaseFold is like Load, but keys are uppercased before being loaded so that
+// files behave the same whether the environment is case sensitive, as on Unix,
+// or not, as on Windows.
+func LoadCaseFold(strict bool, filenames ...string) error {
+	return LoadWithOptions(strict, filenames, CaseFold())
+}
+
+// PrecedenceRule decides, for a key declared in an env file that is already set in
+// the environment, whether the value of the file replaces the current one.
+//
+// The current value may come from an earlier file of the same load.
+type PrecedenceRule func(key, fileVal, envVal string) bool
+
+// FileWins is the PrecedenceRule of Overload, the values of the files always win.
+func FileWins(key, fileVal, envVal string) bool {
+	return true
+}
+
+// EnvWins is the PrecedenceRule of Load, the values already set always win.
+func EnvWins(key, fileVal, envVal string) bool {
+	return false
+}
+
+// FileWinsFor returns a PrecedenceRule under which the values of the files win for
+// the given keys alone, while the environment wins for the others.
+func FileWinsFor(keys ...string) PrecedenceRule {
+	wins := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wins[key] = true
+	}
+	return func(key, fileVal, envVal string) bool {
+		return wins[key]
+	}
+}
+
+// LoadWithPrecedence is like Load, but whether the values of the files override the
+// env variables that already exist is decided key by key by rule:
+//
+//	err := godotenv.LoadWithPrecedence(godotenv.FileWinsFor("LOG_FORMAT"), true)
+func LoadWithPrecedence(rule PrecedenceRule, strict bool, filenames ...string) error {
+	_, err := loadFiles(openFrom("./"), strict, false, filenames, loadOptions{precedence: rule})
+	return err
+}
+
+// Overload will read your env file(s) and load them into ENV for this process.
+//
+// Call this function as close as possible to the start of your program (ideally in main).
+//
+// If you call Overload without any args it will default to loading .env in the current path.
+//
+// You can otherwise tell it which files to load (there can be more than one) like:
+//
+//	godotenv.Overload("fileone", "filetwo")
+//
+// It's important to note this WILL OVERRIDE an env variable that already exists - consider the .env file to forcefully set all vars.
+func Overload(strict bool, filenames ...string) (err error) {
+	return OverloadFrom("./", strict, filenames...)
+}
+
+// OverloadFrom is like Overload, with filenames relative to dir. Filenames can be
+// glob patterns, as with LoadFrom.
+func OverloadFrom(dir string, strict bool, filenames ...string) (err error) {
+	_, err = loadFiles(openFrom(dir), strict, true, filenames, loadOptions{glob: globFrom(dir)})
+	return
+}
+
+// OverloadWithOptions is like Overload, with the way values are applied to the
+// environment tweaked by the given options.
+func OverloadWithOptions(strict bool, filenames []string, opts ...LoadOption) error {
+	_, err := loadFiles(openFrom("./"), strict, true, filenames, newLoadOptions(opts))
+	return err
+}
+
+// Override is the change made to an environment variable that was already set.
+type Override struct {
+	Old, New string
+}
+
+// OverloadResult reports what Overload did to the process environment.
+type OverloadResult struct {
+	// Overridden holds the keys that were already set, with the value they had
+	// before the load and the one they were left with.
+	Overridden map[string]Override
+	// Added lists the keys that weren't set before the load, in file order.
+	Added []string
+}
+
+// OverloadWithResult is like Overload, but also reports the variables it
+// replaced, along with their prior values, and the ones it added, such as to log
+// or roll back what a forced load changed:
+//
+//	result, err := godotenv.OverloadWithResult(true, ".env.production")
+//	for key, change := range result.Overridden {
+//		log.Printf("%s: %q -> %q", key, change.Old, change.New)
+//	}
+func OverloadWithResult(strict bool, filenames ...string) (OverloadResult, error) {
+	overridden := make(map[string]Override)
+	// touched holds the keys this load already set, so that a key added by a
+	// file and redeclared by a later one isn't taken for an existing one.
+	touched := make(map[string]bool)
+	opts := loadOptions{
+		// the rule is only called for keys that are already set, before they
+		// are changed
+		precedence: func(key, fileVal, envVal string) bool {
+			change, ok := overridden[key]
+			if !ok && touched[key] {
+				return true
+			}
+			if !ok {
+				change.Old = envVal
+			}
+			change.New = fileVal
+			overridden[key] = change
+			return true
+		},
+		onKeySet: func(key string) { touched[key] = true },
+	}
+
+	loaded, err := loadFiles(openFrom("./"), strict, true, filenames, opts)
+	result := OverloadResult{Overridden: overridden}
+	for _, key := range loaded.Set {
+		if _, ok := overridden[key]; !ok && touched[key] {
+			result.Added = append(result.Added, key)
+			touched[key] = false
+		}
+	}
+	return result, err
+}
+
+// LoadInto applies the env file(s) to target rather than to the environment, with
+// the same semantics as Load, or as Overload when override is true:
+//
+//	config := map[string]string{"PORT": "8080"}
+//	err := godotenv.LoadInto(config, false, ".env")
+//
+// Files are loaded in strict mode, the first file that fails to be read stopping
+// the load, with the files before it already applied to target.
+func LoadInto(target map[string]string, override bool, filenames ...string) error {
+	open := openFrom("./")
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		pairs, err := readPairs(open, filename)
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			if _, exists := target[pair.Key]; !exists || override {
+				target[pair.Key] = pair.Value
+			}
+		}
+	}
+	return nil
+}
+
+// Read all env (with same file loading semantics as Load) but return values as
+// a map rather than automatically writing values into env
+//
+// In non-strict mode, the files that fail to be read are skipped and reported by
+// a *SkippedFilesError, returned along with the values of the other files:
+//
+//	envMap, err := godotenv.Read(false, ".env", ".env.prod")
+//	if skipped, ok := err.(*godotenv.SkippedFilesError); ok {
+//		log.Print(skipped)
+//	} else if err != nil {
+//		log.Fatal(err)
+//	}
+func Read(strict bool, filenames ...string) (envMap map[string]string, err error) {
+	return ReadFrom("./", strict, filenames...)
+}
+
+// ReadFrom is like Read, with filenames relative to dir. Filenames can be glob
+// patterns, as with LoadFrom.
+func ReadFrom(dir string, strict bool, filenames ...string) (envMap map[string]string, err error) {
+	return readFiles(openFrom(dir), strict, filenames, loadOptions{glob: globFrom(dir)})
+}
+
+// ReadWithOptions is like Read, with the values returned tweaked by the given
+// options. The hooks such as OnKeySet are only called when loading.
+//
+//	envMap, err := godotenv.ReadWithOptions(true, []string{".env"}, godotenv.OmitEmpty())
+func ReadWithOptions(strict bool, filenames []string, opts ...LoadOption) (envMap map[string]string, err error) {
+	return readFiles(openFrom("./"), strict, filenames, newLoadOptions(opts))
+}
+
+// ReadFS reads env file(s) from fsys (with same file loading semantics as Load) but
+// returns values as a map rather than automatically writing values into env
+func ReadFS(fsys fs.FS, strict bool, filenames ...string) (envMap map[string]string, err error) {
+	return readFiles(fsys.Open, strict, filenames, loadOptions{glob: globFS(fsys)})
+}
+
+// ReadMerged reads env file(s) like Read, but returns their merged pairs in a
+// deterministic order.
+//
+// Keys keep the position in which they were first declared across the files,
+// while values declared by later files replace those of earlier ones.
+func ReadMerged(strict bool, filenames ...string) (pairs []Pair, err error) {
+	open := openFrom("./")
+	if filenames, err = expandGlobs(globFrom("./"), filenames); err != nil {
+		return nil, err
+	}
+	loaded := false
+
+	for _, filename := range filenames {
+		filePairs, individualErr := readPairs(open, filename)
+
+		if individualErr != nil && strict {
+			err = individualErr
+			return // return early on a spazout
+		}
+		if individualErr != nil && !strict {
+			continue
+		}
+
+		loaded = true
+		pairs = mergePairs(pairs, filePairs)
+	}
+
+	if !loaded {
+		err = noEnvFileLoadedErr
+	}
+	return
+}
+
+// Unmarshal reads an env file from a string, returning a map of keys and values.
+func Unmarshal(str string) (envMap map[string]string, err error) {
+	return UnmarshalBytes([]byte(str))
+}
+
+// UnmarshalBytes parses env file from byte slice of chars, returning a map of keys and values.
+func UnmarshalBytes(src []byte) (map[string]string, error) {
+	return ParseBytes(src)
+}
+
+// ParseBytes parses an env file held in src, returning a map of keys and values.
+//
+// This is the canonical entry point for content already in memory, such as embedded
+// files or HTTP bodies: src is read in place and never modified.
+func ParseBytes(src []byte, opts ...ParseOption) (map[string]string, error) {
+	return NewParser(opts...).ParseBytes(src)
+}
+
+func unmarshalBytes(src []byte, opts parseOptions) (map[string]string, error) {
+	pairs, err := parseBytes(src, opts)
+
+	out := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		out[pair.Key] = pair.Value
+	}
+	return out, err
+}
+
+// Exec loads env vars from the specified filenames (empty map falls back to default)
+// then executes the cmd specified.
+//
+// Simply hooks up os.Stdin/err/out to the command and calls Run().
+//
+// If you want more fine grained control over your command it's recommended
+// that you use `Load()`, `Overload()` or `Read()` and the `os/exec` package yourself.
+func Exec(filenames []string, cmd string, cmdArgs []string, strict, overload bool) error {
+	return ExecContext(context.Background(), filenames, cmd, cmdArgs, strict, overload)
+}
+
+// ExecContext is like Exec but the command is killed if ctx is done before it exits.
+//
+// While the command runs, SIGINT and SIGTERM received by the current process are
+// forwarded to it instead of terminating the current process, so that godotenv can
+// be used as a wrapper in container entrypoints without orphaning the command.
+func ExecContext(ctx context.Context, filenames []string, cmd string, cmdArgs []string, strict, overload bool) error {
+	return ExecWithOptions(ctx, filenames, cmd, cmdArgs, strict, overload)
+}
+
+// ExecWithOptions is like ExecContext, with the environment of the command tweaked
+// by the given options.
+func ExecWithOptions(ctx context.Context, filenames []string, cmd string, cmdArgs []string, strict, overload bool, opts ...ExecOption) error {
+	env, err := commandEnv(newExecOptions(opts), openFrom("./"), strict, overload, filenames)
+	if err != nil {
+		return err
+	}
+
+	command := exec.CommandContext(ctx, cmd, cmdArgs...)
+	command.Env = env
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return runForwardingSignals(command)
+}
+
+// ExecStatus reports how a command run by ExecResult ended.
+type ExecStatus struct {
+	// ExitCode is the exit code of the command, or -1 if it was terminated by a signal.
+	ExitCode int
+	// Duration is the time the command ran for.
+	Duration time.Duration
+	// Signaled is set when the command was terminated by a signal.
+	Signaled bool
+	// Signal is the signal that terminated the command, when Signaled is set.
+	Signal syscall.Signal
+}
+
+// ExecResult is like Exec, but reports the exit code of the command rather than
+// returning an error when it exits with a non-zero code. An error is only returned
+// when the files can't be loaded or the command can't be run.
+//
+// This allows wrappers to exit with the code of the command:
+//
+//	status, err := godotenv.ExecResult(nil, "make", []string{"test"}, true, false)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	os.Exit(status.ExitCode)
+func ExecResult(filenames []string, cmd string, cmdArgs []string, strict, overload bool) (ExecStatus, error) {
+	if err := loadForExec(filenames, strict, overload); err != nil {
+		return ExecStatus{}, err
+	}
+
+	command := exec.Command(cmd, cmdArgs...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	start := time.Now()
+	err := runForwardingSignals(command)
+	status := ExecStatus{Duration: time.Since(start)}
+	if command.ProcessState == nil {
+		// the command never started
+		return status, err
+	}
+
+	status.ExitCode = command.ProcessState.ExitCode()
+	if ws, ok := command.ProcessState.Sys().(interface {
+		Signaled() bool
+		Signal() syscall.Signal
+	}); ok && ws.Signaled() {
+		status.Signaled = true
+		status.Signal = ws.Signal()
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		err = nil
+	}
+	return status, err
+}
+
+// ExecCapture is like Exec but buffers the output of the command and returns it,
+// rather than hooking it up to os.Stdout and os.Stderr. The command doesn't read
+// from os.Stdin either.
+//
+// The output is returned even if the command fails, so that its error messages can
+// be inspected.
+func ExecCapture(filenames []string, cmd string, cmdArgs []string, strict, overload bool) (stdout, stderr []byte, err error) {
+	if err := loadForExec(filenames, strict, overload); err != nil {
+		return nil, nil, err
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	command := exec.Command(cmd, cmdArgs...)
+	command.Stdout = &outBuf
+	command.Stderr = &errBuf
+	err = command.Run()
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+func loadForExec(filenames []string, strict, overload bool, opts ...LoadOption) error {
+	if overload {
+		return OverloadWithOptions(strict, filenames, opts...)
+	}
+	return LoadWithOptions(strict, filenames, opts...)
+}
+
+// commandEnv loads the env files the way Exec does and returns the environment
+// commands are run with, nil meaning that of the current process.
+func commandEnv(o execOptions, open openFunc, strict, overload bool, filenames []string) ([]string, error) {
+	if !o.isolated && len(o.lists) == 0 {
+		return nil, loadForExec(filenames, strict, overload)
+	}
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	// the values list variables have before any file is loaded
+	inheritedLists := make(map[string]string)
+	for key := range o.lists {
+		if value, ok := os.LookupEnv(key); ok && (!o.isolated || containsString(o.inherit, key)) {
+			inheritedLists[key] = value
+		}
+	}
+
+	var envMap map[string]string
+	if o.isolated {
+		inherited := make(map[string]string)
+		for _, key := range o.inherit {
+			if value, ok := os.LookupEnv(key); ok {
+				inherited[key] = value
+			}
+		}
+		var err error
+		if envMap, err = envFromFiles(open, strict, overload, filenames, inherited); err != nil {
+			return nil, err
+		}
+	} else {
+		// list variables are merged for the command alone, the current process
+		// keeps its own values
+		notList := Filter(func(key string) bool {
+			_, ok := o.lists[key]
+			return !ok
+		})
+		if err := loadForExec(filenames, strict, overload, notList); err != nil {
+			return nil, err
+		}
+		envMap = ParseEnviron(os.Environ())
+	}
+
+	if len(o.lists) > 0 {
+		fileValues, err := envFromFiles(open, strict, overload, filenames, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, list := range o.lists {
+			if value, ok := fileValues[key]; ok {
+				envMap[key] = list.merge(value, inheritedLists[key])
+			}
+		}
+	}
+
+	// a nil Env would make the command inherit the whole environment
+	env := make([]string, 0, len(envMap))
+	for key, value := range envMap {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// envFromFiles returns base with the values of the env files applied to it, with
+// the same precedence as Load or Overload would.
+func envFromFiles(open openFunc, strict, overload bool, filenames []string, base map[string]string) (map[string]string, error) {
+	envMap := make(map[string]string, len(base))
+	for key, value := range base {
+		envMap[key] = value
+	}
+
+	loaded := false
+	for _, filename := range filenamesOrDefault(filenames) {
+		pairs, err := readPairs(open, filename)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			continue
+		}
+
+		loaded = true
+		for _, pair := range pairs {
+			if _, exists := envMap[pair.Key]; !exists || overload {
+				envMap[pair.Key] = pair.Value
+			}
+		}
+	}
+	if !loaded {
+		return nil, noEnvFileLoadedErr
+	}
+	return envMap, nil
+}
+
+func runForwardingSignals(command *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := command.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = command.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return command.Wait()
+}
+
+// Write serializes the given environment and writes it to a file.
+//
+// The file is replaced atomically, readers seeing either its previous content or
+// the new one in full, even if the program stops while writing it.
+func Write(envMap map[string]string, filename string) error {
+	return WriteWithOptions(envMap, filename)
+}
+
+// WriteWithOptions is like Write, with the serialization tweaked by the given options.
+func WriteWithOptions(envMap map[string]string, filename string, opts ...MarshalOption) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return MarshalTo(w, envMap, opts...)
+	})
+}
+
+// WriteOrdered serializes the given pairs and writes them to a file, keeping
+// the order in which they are given.
+func WriteOrdered(pairs []Pair, filename string, opts ...MarshalOption) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return marshalPairsTo(w, pairs, newMarshalOptions(opts))
+	})
+}
+
+// Marshal outputs the given environment as a dotenv-formatted environment file.
+// Each line is in the format: KEY="VALUE" where VALUE is backslash-escaped.
+func Marshal(envMap map[string]string) (string, error) {
+	return MarshalWithOptions(envMap)
+}
+
+// MarshalWithOptions is like Marshal, with the serialization tweaked by the given options:
+//
+//	content, err := godotenv.MarshalWithOptions(env, godotenv.MinimalQuoting())
+func MarshalWithOptions(envMap map[string]string, opts ...MarshalOption) (string, error) {
+	var sb strings.Builder
+	if err := MarshalTo(&sb, envMap, opts...); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// MarshalTyped is like MarshalWithOptions, with the values given as Go values
+// rather than strings, formatted the way the getters read them back: strings as
+// they are, integers and floats in decimal, bools as true or false and
+// time.Duration values as by their String method, such as 1m30s.
+//
+//	content, err := godotenv.MarshalTyped(map[string]interface{}{
+//		"PORT":    8080,
+//		"DEBUG":   true,
+//		"TIMEOUT": 30 * time.Second,
+//	})
+//
+// It errors on values of any other type.
+func MarshalTyped(m map[string]interface{}, opts ...MarshalOption) (string, error) {
+	envMap := make(map[string]string, len(m))
+	for key, value := range m {
+		formatted, err := formatValue(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", key, err)
+		}
+		envMap[key] = formatted
 	}
+	return MarshalWithOptions(envMap, opts...)
+}
 
-	return UnmarshalBytes(buf.Bytes())
+// formatValue formats value as a string for MarshalTyped.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Duration:
+		return v.String(), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
 }
 
-// Load will read your env file(s) and load them into ENV for this process.
+// MarshalExport outputs the given environment as a file that can be both read by
+// godotenv and sourced by a shell, each line being in the format export KEY='VALUE'.
+func MarshalExport(envMap map[string]string) (string, error) {
+	return MarshalWithOptions(envMap, ExportPrefix(), ShellQuoting())
+}
+
+// MarshalWithComments is like MarshalWithOptions, with the comment of each key in
+// comments written on the lines before it:
 //
-// Call this function as close as possible to the start of your program (ideally in main).
+//	content, err := godotenv.MarshalWithComments(
+//		map[string]string{"WORKERS": "4"},
+//		map[string]string{"WORKERS": "number of worker threads"},
+//	)
 //
-// If you call Load without any args it will default to loading .env in the current path.
+// gives
 //
-// You can otherwise tell it which files to load (there can be more than one) like:
+//	# number of worker threads
+//	WORKERS=4
 //
-//	godotenv.Load("fileone", "filetwo")
+// Comments spanning several lines are written as several comment lines.
+func MarshalWithComments(envMap map[string]string, comments map[string]string, opts ...MarshalOption) (string, error) {
+	opts = append(opts, func(o *marshalOptions) {
+		o.comments = comments
+	})
+	return MarshalWithOptions(envMap, opts...)
+}
+
+// Canonicalize returns a stable representation of envMap, meant to be hashed or
+// compared to tell whether two configurations are the same:
 //
-// It's important to note that it WILL NOT OVERRIDE an env variable that already exists - consider the .env file to set dev vars or sensible defaults.
-func Load(strict bool, filenames ...string) (err error) {
-	return LoadFrom("./", strict, filenames...)
+//	envMap, _ := godotenv.Read()
+//	sum := sha256.Sum256([]byte(godotenv.Canonicalize(envMap)))
+//
+// Keys are sorted and every value is written as a Go quoted string, so that maps
+// holding the same keys and values always give the same string, whatever the
+// formatting of the files they were read from. Unlike Marshal, the result isn't
+// meant to be read back as an env file.
+func Canonicalize(envMap map[string]string) string {
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(envMap[key]))
+		b.WriteByte('\n')
+	}
+	return b.String()
 }
 
-func LoadFrom(dir string, strict bool, filenames ...string) (err error) {
-	filenames = filenamesOrDefault(filenames)
-	loaded := false
+// MarshalTo writes the given environment to w in the same format as Marshal, one
+// line at a time and each line terminated by a newline. An empty environment is
+// written as a single newline, as Write always has.
+func MarshalTo(w io.Writer, envMap map[string]string, opts ...MarshalOption) error {
+	o := newMarshalOptions(opts)
+	if len(envMap) == 0 && !o.noTrailingNewline {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	pairs := make([]Pair, 0, len(envMap))
+	lines := make(map[string]string, len(envMap))
+	for k, v := range envMap {
+		pairs = append(pairs, Pair{Key: k, Value: v})
+		lines[k] = marshalLine(k, v, o)
+	}
+	// sorted by line rather than by key, as Marshal always has, which puts A0=1 before A=1
+	sort.Slice(pairs, func(i, j int) bool {
+		return lines[pairs[i].Key] < lines[pairs[j].Key]
+	})
+	return marshalPairsTo(w, pairs, o)
+}
 
-	for _, filename := range filenames {
-		innerErr := loadFile(dir, filename, false)
-		if innerErr != nil && strict {
-			err = innerErr
-			return // return early on a spazout
+// MarshalOrdered outputs the given pairs as a dotenv-formatted environment file.
+// Unlike Marshal, lines are emitted in the order of the pairs rather than sorted.
+func MarshalOrdered(pairs []Pair, opts ...MarshalOption) (string, error) {
+	var sb strings.Builder
+	if err := marshalPairsTo(&sb, pairs, newMarshalOptions(opts)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+func marshalPairsTo(w io.Writer, pairs []Pair, opts marshalOptions) error {
+	for i, pair := range pairs {
+		if opts.shellQuoting && strings.Contains(pair.Value, "\r") {
+			return fmt.Errorf("%s: carriage returns can't be single quoted, they would be read back as line breaks", pair.Key)
 		}
-		if innerErr == nil {
-			loaded = true
+		if comment, ok := opts.comments[pair.Key]; ok {
+			if _, err := io.WriteString(w, commentLines(comment)); err != nil {
+				return err
+			}
+		}
+		line := marshalLine(pair.Key, pair.Value, opts)
+		if i < len(pairs)-1 || !opts.noTrailingNewline {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentLines returns comment as comment lines, one for each of its lines.
+func commentLines(comment string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			b.WriteString("#\n")
+			continue
 		}
+		b.WriteString("# " + line + "\n")
 	}
+	return b.String()
+}
 
-	if !loaded {
-		err = noEnvFileLoadedErr
+func marshalLine(key, value string, opts marshalOptions) string {
+	line := key + opts.separator + marshalValue(value, opts)
+	if opts.export {
+		return exportPrefix + " " + line
 	}
-	return
+	return line
 }
 
-// Overload will read your env file(s) and load them into ENV for this process.
-//
-// Call this function as close as possible to the start of your program (ideally in main).
+func marshalValue(value string, opts marshalOptions) string {
+	if opts.shellQuoting {
+		return shellQuote(value)
+	}
+	// integers are written unquoted, as long as they are written back exactly as
+	// they are, so that 01 or +1 keep their quotes and their leading characters
+	if d, err := strconv.Atoi(value); err == nil && strconv.Itoa(d) == value {
+		return value
+	}
+	if opts.minimalQuoting && !needsQuoting(value) {
+		return value
+	}
+	if opts.multilineBlocks && strings.Contains(value, "\n") && blockQuotable(value) {
+		return `"""` + "\n" + value + `"""`
+	}
+	return `"` + opts.escape(value) + `"`
+}
+
+// blockQuotable reports whether value can be written in a """ delimited block
+// and read back as is: it can't hold """ nor carriage returns, and a trailing "
+// would be read as the start of the closing delimiter.
+func blockQuotable(value string) bool {
+	return !strings.Contains(value, `"""`) && !strings.Contains(value, "\r") &&
+		!strings.HasSuffix(value, `"`)
+}
+
+// shellQuote single quotes value so that both a shell and the parser read it back
+// as is.
 //
-// If you call Overload without any args it will default to loading .env in the current path.
+// Single quotes, and backslashes that would end up right before a closing quote,
+// are escaped with a backslash outside of the quotes, as in:
 //
-// You can otherwise tell it which files to load (there can be more than one) like:
+//	'it'\''s'
+func shellQuote(value string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	quoted := true
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		escape := c == '\''
+		if c == '\\' {
+			end := i
+			for end < len(value) && value[end] == '\\' {
+				end++
+			}
+			escape = end == len(value) || value[end] == '\''
+			if !escape {
+				if !quoted {
+					b.WriteByte('\'')
+					quoted = true
+				}
+				b.WriteString(value[i:end])
+				i = end - 1
+				continue
+			}
+		}
+
+		if escape {
+			if quoted {
+				b.WriteByte('\'')
+				quoted = false
+			}
+			b.WriteByte('\\')
+			b.WriteByte(c)
+			continue
+		}
+		if !quoted {
+			b.WriteByte('\'')
+			quoted = true
+		}
+		b.WriteByte(c)
+	}
+	if quoted {
+		b.WriteByte('\'')
+	}
+	return b.String()
+}
+
+// needsQuoting reports whether value can't be written unquoted and read back as is.
+func needsQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune(unquotedSafeChars, c):
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile atomically replaces filename with what write writes, which goes to
+// a temporary file of the same directory renamed over filename once it is
+// complete, so that readers never see a partially written file.
 //
-//	godotenv.Overload("fileone", "filetwo")
+// An existing file keeps its permissions, and a symbolic link is followed so that
+// the file it points to is replaced rather than the link. A new file gets the
+// permissions os.Create would give it, the umask applied to 0666.
+func writeFile(filename string, write func(w io.Writer) error) (err error) {
+	var mode os.FileMode
+	exists := false
+	if info, statErr := os.Stat(filename); statErr == nil {
+		mode, exists = info.Mode().Perm(), true
+		if filename, err = filepath.EvalSymlinks(filename); err != nil {
+			return err
+		}
+	}
+
+	file, err := createTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	buf := bufio.NewWriter(file)
+	if err = write(buf); err != nil {
+		return err
+	}
+	if err = buf.Flush(); err != nil {
+		return err
+	}
+	if exists {
+		if err = file.Chmod(mode); err != nil {
+			return err
+		}
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filename)
+}
+
+// createTemp creates a new file in dir, named prefix followed by a random number,
+// as os.CreateTemp does, but with the permissions os.Create would give it rather
+// than 0600.
+func createTemp(dir, prefix string) (*os.File, error) {
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// defaultFilename is the file read when no filenames are given.
+var defaultFilename = ".env"
+
+// SetDefaultFilename changes the file read by Load, Read and the other functions
+// when they are called without filenames, such as config.env, from the default .env.
 //
-// It's important to note this WILL OVERRIDE an env variable that already exists - consider the .env file to forcefully set all vars.
-func Overload(strict bool, filenames ...string) (err error) {
-	return OverloadFrom("./", strict, filenames...)
+// It is meant to be called once, before any file is loaded, and isn't safe for
+// concurrent use.
+func SetDefaultFilename(name string) {
+	defaultFilename = name
 }
 
-func OverloadFrom(dir string, strict bool, filenames ...string) (err error) {
-	filenames = filenamesOrDefault(filenames)
+func filenamesOrDefault(filenames []string) []string {
+	if len(filenames) == 0 {
+		return []string{defaultFilename}
+	}
+	return filenames
+}
+
+// openFunc opens the named env file for reading.
+type openFunc func(name string) (fs.File, error)
+
+// openFile opens filename with open, reporting a missing file with ErrFileNotFound.
+func openFile(open openFunc, filename string) (fs.File, error) {
+	file, err := open(filename)
+	var pathErr *fs.PathError
+	if errors.Is(err, fs.ErrNotExist) && errors.As(err, &pathErr) {
+		pathErr.Err = &fileNotFoundError{err: pathErr.Err}
+	}
+	return file, err
+}
+
+// globFunc returns the names of the env files matching pattern, as filepath.Glob.
+type globFunc func(pattern string) ([]string, error)
+
+// globFrom returns a globFunc matching the files of dir, their names relative to it.
+func globFrom(dir string) globFunc {
+	return func(pattern string) ([]string, error) {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for i, match := range matches {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			matches[i] = filepath.ToSlash(rel)
+		}
+		return matches, nil
+	}
+}
+
+// globFS returns a globFunc matching the files of fsys.
+func globFS(fsys fs.FS) globFunc {
+	return func(pattern string) ([]string, error) {
+		return fs.Glob(fsys, pattern)
+	}
+}
+
+// expandGlobs returns filenames, or the default file when there are none, with
+// their glob patterns replaced by the files glob matches in sorted order. Patterns
+// matching nothing are kept, to be reported as missing.
+func expandGlobs(glob globFunc, filenames []string) ([]string, error) {
+	var expanded []string
+	for _, filename := range filenamesOrDefault(filenames) {
+		if !strings.ContainsAny(filename, "*?[") {
+			expanded = append(expanded, filename)
+			continue
+		}
+
+		matches, err := glob(filename)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			expanded = append(expanded, filename)
+			continue
+		}
+		sort.Strings(matches)
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+func openFrom(dir string) openFunc {
+	return func(filename string) (fs.File, error) {
+		return os.Open(path.Join(dir, filename))
+	}
+}
+
+// openWithin is like openFrom, but refuses the files that resolve outside of dir.
+func openWithin(dir string) openFunc {
+	return func(filename string) (fs.File, error) {
+		name := filepath.Join(dir, filepath.FromSlash(filename))
+		if !isWithin(dir, name) {
+			return nil, &fs.PathError{Op: "open", Path: filename, Err: ErrOutsideRoot}
+		}
+
+		// a symlink can still point outside of dir, resolve it if it exists
+		if real, err := filepath.EvalSymlinks(name); err == nil {
+			root, err := filepath.EvalSymlinks(dir)
+			if err != nil {
+				return nil, err
+			}
+			if !isWithin(root, real) {
+				return nil, &fs.PathError{Op: "open", Path: filename, Err: ErrOutsideRoot}
+			}
+		}
+		return os.Open(name)
+	}
+}
+
+// isWithin reports whether name is dir or lies under it, both being compared
+// lexically.
+func isWithin(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func loadFiles(open openFunc, strict, overload bool, filenames []string, opts loadOptions) (result Result, err error) {
+	if filenames, err = expandGlobs(opts.globFunc(), filenames); err != nil {
+		return
+	}
 	loaded := false
+	opts.strict = strict
 
 	for _, filename := range filenames {
-		innerErr := loadFile(dir, filename, true)
-		if innerErr != nil && strict {
+		innerErr := loadFile(open, filename, overload, opts, &result)
+		if innerErr != nil && opts.onError != nil {
+			opts.onError(filename, innerErr)
+		}
+		if innerErr != nil && strict && !(opts.optional && errors.Is(innerErr, ErrFileNotFound)) {
 			err = innerErr
 			return // return early on a spazout
 		}
-		if innerErr != nil && !strict {
-			continue
+		if innerErr == nil {
+			loaded = true
+			result.FilesLoaded = append(result.FilesLoaded, filename)
 		}
-		loaded = true
 	}
 
 	if !loaded {
 		err = noEnvFileLoadedErr
+		return
 	}
+	applyOverrides(opts.overrides, &result)
 	return
 }
 
-// Read all env (with same file loading semantics as Load) but return values as
-// a map rather than automatically writing values into env
-func Read(strict bool, filenames ...string) (envMap map[string]string, err error) {
-	return ReadFrom("./", strict, filenames...)
+// applyOverrides sets overrides into the environment whatever the current values,
+// recording them in result.
+func applyOverrides(overrides map[string]string, result *Result) {
+	if len(overrides) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	envMu.Lock()
+	defer envMu.Unlock()
+	for _, key := range keys {
+		_ = os.Setenv(key, overrides[key])
+		result.Overridden = append(result.Overridden, key)
+	}
 }
 
-func ReadFrom(dir string, strict bool, filenames ...string) (envMap map[string]string, err error) {
-	filenames = filenamesOrDefault(filenames)
+func readFiles(open openFunc, strict bool, filenames []string, opts loadOptions) (envMap map[string]string, err error) {
+	if filenames, err = expandGlobs(opts.globFunc(), filenames); err != nil {
+		return nil, err
+	}
 	envMap = make(map[string]string)
 	loaded := false
+	var skipped []*FileError
+	opts.strict = strict
 
 	for _, filename := range filenames {
-		individualEnvMap, individualErr := readFile(dir, filename)
+		pairs, individualErr := readPairs(open, filename, opts.parseOpts()...)
+		if individualErr == nil {
+			pairs, individualErr = opts.apply(pairs)
+		}
 
 		if individualErr != nil && strict {
 			err = individualErr
 			return // return early on a spazout
 		}
 		if individualErr != nil && !strict {
+			skipped = append(skipped, &FileError{Filename: filename, Err: individualErr})
 			continue
 		}
 
 		loaded = true
-		for key, value := range individualEnvMap {
-			envMap[key] = value
+		for _, pair := range pairs {
+			envMap[pair.Key] = pair.Value
 		}
 	}
 
 	if !loaded {
 		err = noEnvFileLoadedErr
+	} else if len(skipped) > 0 {
+		err = &SkippedFilesError{Files: skipped}
 	}
 	return
 }
 
-// Unmarshal reads an env file from a string, returning a map of keys and values.
-func Unmarshal(str string) (envMap map[string]string, err error) {
-	return UnmarshalBytes([]byte(str))
-}
-
-// UnmarshalBytes parses env file from byte slice of chars, returning a map of keys and values.
-func UnmarshalBytes(src []byte) (map[string]string, error) {
-	out := make(map[string]string)
-	err := parseBytes(src, out)
-
-	return out, err
-}
-
-// Exec loads env vars from the specified filenames (empty map falls back to default)
-// then executes the cmd specified.
-//
-// Simply hooks up os.Stdin/err/out to the command and calls Run().
-//
-// If you want more fine grained control over your command it's recommended
-// that you use `Load()`, `Overload()` or `Read()` and the `os/exec` package yourself.
-func Exec(filenames []string, cmd string, cmdArgs []string, strict, overload bool) error {
-	op := Load
-	if overload {
-		op = Overload
-	}
-	if err := op(strict, filenames...); err != nil {
+func loadFile(open openFunc, filename string, overload bool, opts loadOptions, result *Result) error {
+	pairs, err := readPairs(open, filename, opts.parseOpts()...)
+	if err != nil {
 		return err
 	}
-
-	command := exec.Command(cmd, cmdArgs...)
-	command.Stdin = os.Stdin
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
-	return command.Run()
+	return loadPairs(filename, pairs, overload, opts, result)
 }
 
-// Write serializes the given environment and writes it to a file.
-func Write(envMap map[string]string, filename string) error {
-	content, err := Marshal(envMap)
+// loadPairs applies the pairs read from filename to the environment once the
+// options are applied, calling the hooks.
+func loadPairs(filename string, pairs []Pair, overload bool, opts loadOptions, result *Result) error {
+	pairs, err := opts.apply(pairs)
 	if err != nil {
 		return err
 	}
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	setBefore := len(result.Set)
+	applyPairs(pairs, opts.precedenceRule(overload), result)
+
+	if opts.onKeySet != nil {
+		for _, key := range result.Set[setBefore:] {
+			opts.onKeySet(key)
+		}
 	}
-	defer file.Close()
-	_, err = file.WriteString(content + "\n")
-	if err != nil {
-		return err
+	if opts.onFileLoaded != nil {
+		opts.onFileLoaded(filename, len(pairs))
 	}
-	return file.Sync()
+	return nil
 }
 
-// Marshal outputs the given environment as a dotenv-formatted environment file.
-// Each line is in the format: KEY="VALUE" where VALUE is backslash-escaped.
-func Marshal(envMap map[string]string) (string, error) {
-	lines := make([]string, 0, len(envMap))
-	for k, v := range envMap {
-		if d, err := strconv.Atoi(v); err == nil {
-			lines = append(lines, fmt.Sprintf(`%s=%d`, k, d))
+// envMu guards the environment of the process while it is changed, so that
+// concurrent loads don't both see a key as unset and then set it.
+var envMu sync.Mutex
+
+// applyPairs sets pairs into the environment, recording what it does in result.
+// Keys that are already set are only overridden when rule says so.
+func applyPairs(pairs []Pair, rule PrecedenceRule, result *Result) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	for _, pair := range pairs {
+		// os.LookupEnv follows the platform rules, so that keys are matched
+		// regardless of their case on Windows.
+		if current, exists := os.LookupEnv(pair.Key); !exists || rule(pair.Key, pair.Value, current) {
+			_ = os.Setenv(pair.Key, pair.Value)
+			result.Set = append(result.Set, pair.Key)
 		} else {
-			lines = append(lines, fmt.Sprintf(`%s="%s"`, k, doubleQuoteEscape(v)))
+			result.Skipped = append(result.Skipped, pair.Key)
 		}
 	}
-	sort.Strings(lines)
-	return strings.Join(lines, "\n"), nil
 }
 
-func filenamesOrDefault(filenames []string) []string {
-	if len(filenames) == 0 {
-		return []string{".env"}
+// mergePairs returns base with its values replaced by those of override, keys
+// only found in override being appended in their order.
+func mergePairs(base, override []Pair) []Pair {
+	index := make(map[string]int, len(base))
+	for i, pair := range base {
+		index[pair.Key] = i
 	}
-	return filenames
+
+	for _, pair := range override {
+		if i, ok := index[pair.Key]; ok {
+			base[i].Value = pair.Value
+			continue
+		}
+		index[pair.Key] = len(base)
+		base = append(base, pair)
+	}
+	return base
 }
 
-func loadFile(dir, filename string, overload bool) error {
-	envMap, err := readFile(dir, filename)
+func readFile(open openFunc, filename string) (envMap map[string]string, err error) {
+	pairs, err := readPairs(open, filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	currentEnv := map[string]bool{}
-	rawEnv := os.Environ()
-	for _, rawEnvLine := range rawEnv {
-		key := strings.Split(rawEnvLine, "=")[0]
-		currentEnv[key] = true
+	envMap = make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		envMap[pair.Key] = pair.Value
 	}
+	return envMap, nil
+}
 
-	for key, value := range envMap {
-		if !currentEnv[key] || overload {
-			_ = os.Setenv(key, value)
+func readPairs(open openFunc, filename string, opts ...ParseOption) (pairs []Pair, err error) {
+	return importPairs(open, filename, newParseOptions(opts), nil)
+}
+
+// importPairs reads the pairs of filename, along with those of the files it
+// imports with @import lines, relative to its directory. chain lists the files
+// importing filename, to detect import cycles.
+func importPairs(open openFunc, filename string, opts parseOptions, chain []string) ([]Pair, error) {
+	for _, importer := range chain {
+		if importer == filename {
+			return nil, fmt.Errorf("import cycle %s", strings.Join(append(chain, filename), " -> "))
 		}
 	}
+	if len(chain) > maxImportDepth {
+		return nil, fmt.Errorf("%s is imported through more than %d files", filename, maxImportDepth)
+	}
 
-	return nil
+	src, err := readSource(open, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	chain = append(chain[:len(chain):len(chain)], filename)
+	opts.importFile = func(name string) ([]Pair, error) {
+		pairs, err := importPairs(open, path.Join(path.Dir(filename), name), opts, chain)
+		if parseErr, ok := err.(*ParseError); ok {
+			return nil, &FileError{Filename: name, Err: parseErr}
+		}
+		return pairs, err
+	}
+	return parseBytes(src, opts)
 }
 
-func readFile(dir, filename string) (envMap map[string]string, err error) {
-	file, err := os.Open(path.Join(dir, filename))
+// readSource returns the normalized content of the env file filename.
+func readSource(open openFunc, filename string) ([]byte, error) {
+	file, err := openFile(open, filename)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer file.Close()
 
-	return Parse(file)
+	r, err := decompress(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+	return normalizeSource(buf.Bytes()), nil
+}
+
+// gzipMagic starts every gzip compressed file.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns a reader of the decompressed content of r when it is gzip
+// compressed, such as a .env.gz file, or of its content as it is otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err != nil || !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
 }
 
-func doubleQuoteEscape(line string) string {
+// DoubleQuoteEscape escapes value to be written between double quotes, the way
+// Marshal does by default: backslashes, double quotes, !, $ and backticks are
+// preceded by a backslash, while newlines and carriage returns are written as
+// \n and \r.
+//
+// It is meant as a base for the escapers given to EscapeWith.
+func DoubleQuoteEscape(line string) string {
 	for _, c := range doubleQuoteSpecialChars {
 		toReplace := "\\" + string(c)
 		if c == '\n' {
@@ -0,0 +1,97 @@
+package godotenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeEnvFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImport(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		"app/.env":            "NAME=app\nPORT=1000\n@import ../shared/common.env\nURL=http://${HOST}:${PORT}\n",
+		"shared/common.env":   "PORT=8080\n@import defaults.env # relative to shared\n",
+		"shared/defaults.env": "HOST=localhost\nNAME=default\n",
+	})
+
+	pairs, err := readPairs(openFrom(dir), "app/.env")
+	if err != nil {
+		t.Fatalf("Expected the imports to be read, got %v", err)
+	}
+	expected := []Pair{
+		{Key: "NAME", Value: "default"},
+		{Key: "PORT", Value: "8080"},
+		{Key: "HOST", Value: "localhost"},
+		{Key: "URL", Value: "http://localhost:8080"},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected %v, got %v", expected, pairs)
+	}
+
+	if _, err := Unmarshal("@import common.env"); err == nil {
+		t.Error("Expected imports to be rejected when parsing from a reader")
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		"cycle.env":   "A=1\n@import other.env\n",
+		"other.env":   "@import cycle.env\n",
+		"missing.env": "A=1\n@import nowhere.env\n",
+		"invalid.env": "@import broken.env\n",
+		"broken.env":  "A=1\nINVALID LINE\n",
+	})
+
+	cases := map[string]string{
+		"cycle.env":   "import cycle cycle.env -> other.env -> cycle.env",
+		"missing.env": "line 2: \"@import nowhere.env\": open",
+		"invalid.env": "line 1: \"@import broken.env\": broken.env: line 2:",
+	}
+	for filename, message := range cases {
+		_, err := readPairs(openFrom(dir), filename)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected %s to fail with %q, got %v", filename, message, err)
+		}
+	}
+
+	files := make(map[string]string)
+	for i := 0; i <= maxImportDepth+1; i++ {
+		files[filepath.Join("deep", string(rune('a'+i))+".env")] = "@import " + string(rune('a'+i+1)) + ".env\n"
+	}
+	dir = writeEnvFiles(t, files)
+	if _, err := readPairs(openFrom(dir), "deep/a.env"); err == nil || !strings.Contains(err.Error(), "imported through more than") {
+		t.Errorf("Expected deep imports to fail, got %v", err)
+	}
+}
+
+func TestSetWithImport(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{".env": "@import common.env\nA=1\n"})
+	filename := filepath.Join(dir, ".env")
+
+	if err := Set(filename, "A", "two"); err != nil {
+		t.Fatalf("Expected A to be set, got %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "@import common.env\nA=two\n"; string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
@@ -0,0 +1,31 @@
+package godotenv
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalJSON outputs the given environment as a JSON object, with its keys sorted.
+//
+// Every value is written as a JSON string, numeric looking ones included, so that
+// values such as 007 or large integers are kept exactly as they are.
+func MarshalJSON(envMap map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// values such as URLs are meant to be read back as they are, not embedded in HTML
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(envMap); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ToJSON reads env file(s) (with the same defaults as Load) and outputs their
+// merged values as a JSON object, as MarshalJSON does.
+func ToJSON(filenames ...string) ([]byte, error) {
+	envMap, err := Read(true, filenames...)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalJSON(envMap)
+}
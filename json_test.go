@@ -0,0 +1,49 @@
+package godotenv
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	envMap := map[string]string{
+		"PORT":  "007",
+		"BIG":   "123456789012345678901234567890",
+		"URL":   "http://localhost/?a=1&b=2",
+		"EMPTY": "",
+	}
+
+	actual, err := MarshalJSON(envMap)
+	if err != nil {
+		t.Fatalf("Expected env to marshal, got %v", err)
+	}
+
+	expected := `{"BIG":"123456789012345678901234567890","EMPTY":"","PORT":"007","URL":"http://localhost/?a=1&b=2"}`
+	if string(actual) != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	actual, err := ToJSON("fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Expected fixture to be exported, got %v", err)
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal(actual, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	envMap, err := Read(true, "fixtures/plain.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(envMap, decoded) {
+		t.Errorf("Expected %v, got %v", envMap, decoded)
+	}
+
+	if _, err := ToJSON("fixtures/missing.env"); err == nil {
+		t.Error("Expected a missing file to fail to be exported")
+	}
+}
@@ -0,0 +1,33 @@
+package godotenv
+
+// Keys returns the keys declared in the env file(s) (with the same defaults as
+// Load), each mapped to the files declaring it in the order they were given,
+// without touching the environment.
+//
+// Keys declared by more than one file, which may override each other by mistake,
+// are the ones mapped to several files:
+//
+//	keys, err := godotenv.Keys(".env", ".env.local")
+//	for key, files := range keys {
+//		if len(files) > 1 {
+//			log.Printf("%s is declared in %v", key, files)
+//		}
+//	}
+func Keys(filenames ...string) (map[string][]string, error) {
+	open := openFrom("./")
+	keys := make(map[string][]string)
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		envMap, err := readFile(open, filename)
+		if err != nil {
+			return nil, err
+		}
+		for key := range envMap {
+			keys[key] = append(keys[key], filename)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,25 @@
+package godotenv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	keys, err := Keys("fixtures/plain.env", "fixtures/equals.env")
+	if err != nil {
+		t.Fatalf("Expected the files to be read, got %v", err)
+	}
+
+	if expected := []string{"fixtures/plain.env", "fixtures/equals.env"}; !reflect.DeepEqual(keys["OPTION_A"], expected) {
+		t.Errorf("Expected OPTION_A to be declared in %v, got %v", expected, keys["OPTION_A"])
+	}
+	if expected := []string{"fixtures/plain.env"}; !reflect.DeepEqual(keys["OPTION_B"], expected) {
+		t.Errorf("Expected OPTION_B to be declared in %v, got %v", expected, keys["OPTION_B"])
+	}
+
+	if _, err := Keys("fixtures/plain.env", "fixtures/missing.env"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected a missing file to fail, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package godotenv
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity tells how serious an Issue is.
+type Severity int
+
+const (
+	// SeverityWarning flags declarations that are read fine, but are likely mistakes.
+	SeverityWarning Severity = iota
+	// SeverityError flags files that can't be read at all.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a problem found by Lint.
+type Issue struct {
+	File string
+	// Line is the 1-based line the problem is on, or 0 when it concerns the whole file.
+	Line     int
+	Severity Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+}
+
+// spacedSeparator matches declarations with whitespace around =.
+var spacedSeparator = regexp.MustCompile(`\s=|=\s+$`)
+
+// Lint checks the env file(s) (with the same defaults as Load) for common
+// mistakes, returning the issues found in file order, for use in pre-commit hooks
+// or CI:
+//
+//	for _, issue := range godotenv.Lint(".env", ".env.example") {
+//		fmt.Println(issue)
+//	}
+//
+// Files that can't be read or parsed are reported as errors. The declarations of
+// the other files are reported as warnings when:
+//   - the key holds lowercase letters
+//   - the key is already declared, in the same file or an earlier one
+//   - there is whitespace around =
+//   - an unquoted value is followed by trailing whitespace
+//   - an unquoted value looks like JSON
+func Lint(filenames ...string) []Issue {
+	var issues []Issue
+	declared := make(map[string]string)
+	open := openFrom("./")
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	for _, filename := range filenames {
+		src, err := readSource(open, filename)
+		if err == nil {
+			_, err = readPairs(open, filename)
+		}
+		if err != nil {
+			issue := Issue{File: filename, Severity: SeverityError, Message: err.Error()}
+			if parseErr, ok := err.(*ParseError); ok {
+				issue.Line = parseErr.Line
+				issue.Message = parseErr.Err.Error()
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		statements, err := scanStatements(src, parseOptions{})
+		if err != nil {
+			issues = append(issues, Issue{File: filename, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		for _, statement := range statements {
+			if statement.key == "" {
+				continue
+			}
+			line := lineNumber(src, src[statement.start:])
+			location := fmt.Sprintf("%s:%d", filename, line)
+			for _, message := range lintStatement(src, statement, declared[statement.key]) {
+				issues = append(issues, Issue{File: filename, Line: line, Severity: SeverityWarning, Message: message})
+			}
+			if _, ok := declared[statement.key]; !ok {
+				declared[statement.key] = location
+			}
+		}
+	}
+	return issues
+}
+
+// Check parses each of the env file(s) (with the same defaults as Load) on its
+// own, without loading nor merging them, and returns a *FileError wrapping the
+// error of the first file that doesn't parse, such as for a CI gate:
+//
+//	if err := godotenv.Check(".env.example"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// On top of the checks Read makes, keys must be accepted by StrictKeys and be
+// declared once per file. The errors of malformed files are *ParseError, giving
+// the line at fault.
+func Check(filenames ...string) error {
+	open := openFrom("./")
+	filenames, err := expandGlobs(globFrom("./"), filenames)
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		if _, err := readPairs(open, filename, StrictKeys(), DisallowDuplicates()); err != nil {
+			return &FileError{Filename: filename, Err: err}
+		}
+	}
+	return nil
+}
+
+// lintStatement returns the problems of statement, previous being the location
+// of an earlier declaration of its key, if any.
+func lintStatement(src []byte, statement rawStatement, previous string) []string {
+	var messages []string
+	key := statement.key
+	if key != strings.ToUpper(key) {
+		messages = append(messages, fmt.Sprintf("%s holds lowercase letters", key))
+	}
+	if previous != "" {
+		messages = append(messages, fmt.Sprintf("%s is already declared at %s", key, previous))
+	}
+	if declaration := src[statement.start:statement.valueStart]; spacedSeparator.Match(declaration) {
+		messages = append(messages, fmt.Sprintf("%s has whitespace around =", key))
+	}
+
+	raw := src[statement.valueStart:statement.end]
+	if hasValueDelimiter(raw) {
+		return messages
+	}
+	length := valueLength(raw)
+	if rest := raw[length:]; len(rest) > 0 && len(bytes.TrimLeftFunc(rest, isSpace)) == 0 {
+		messages = append(messages, fmt.Sprintf("%s has trailing whitespace after its value", key))
+	}
+	if value := raw[:length]; len(value) > 0 && (value[0] == '{' || value[0] == '[') {
+		messages = append(messages, fmt.Sprintf("%s looks like unquoted JSON, quote it with single quotes", key))
+	}
+	return messages
+}
@@ -0,0 +1,84 @@
+package godotenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.env": "# clean\nexport NAME=godotenv\nPORT = 8080\nlower_key=1\nPADDED=value  \n" +
+			"COMMENTED=value   # fine\nCONFIG={\"debug\": true}\nQUOTED='{\"debug\": true}'\nYAML: style\n",
+		"local.env":   "NAME=override\nNAME=twice\n",
+		"invalid.env": "VALID=1\nINVALID LINE\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chdir(t, dir)
+
+	issues := Lint("base.env", "local.env", "invalid.env", "missing.env")
+
+	expected := []Issue{
+		{File: "base.env", Line: 3, Severity: SeverityWarning, Message: "PORT has whitespace around ="},
+		{File: "base.env", Line: 4, Severity: SeverityWarning, Message: "lower_key holds lowercase letters"},
+		{File: "base.env", Line: 5, Severity: SeverityWarning, Message: "PADDED has trailing whitespace after its value"},
+		{File: "base.env", Line: 7, Severity: SeverityWarning, Message: "CONFIG looks like unquoted JSON, quote it with single quotes"},
+		{File: "local.env", Line: 1, Severity: SeverityWarning, Message: "NAME is already declared at base.env:2"},
+		{File: "local.env", Line: 2, Severity: SeverityWarning, Message: "NAME is already declared at base.env:2"},
+		{File: "invalid.env", Line: 2, Severity: SeverityError, Message: `unexpected character "\n" in variable name`},
+	}
+	if len(issues) != len(expected)+1 {
+		t.Fatalf("Expected %d issues, got %v", len(expected)+1, issues)
+	}
+	if !reflect.DeepEqual(issues[:len(expected)], expected) {
+		t.Errorf("Expected %v, got %v", expected, issues[:len(expected)])
+	}
+	if missing := issues[len(expected)]; missing.File != "missing.env" || missing.Line != 0 || missing.Severity != SeverityError {
+		t.Errorf("Expected the missing file to be reported as an error, got %v", missing)
+	}
+
+	if s := expected[0].String(); s != "base.env:3: warning: PORT has whitespace around =" {
+		t.Errorf("Unexpected issue rendering %q", s)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"valid.env":      "NAME=godotenv\nPORT=8080\n",
+		"duplicate.env":  "NAME=a\nPORT=1\nNAME=b\n",
+		"invalid.env":    "VALID=1\nINVALID LINE\n",
+		"strict_key.env": "VALID=1\nDB.HOST=localhost\n",
+		"imports.env":    "@import valid.env\nPORT=9090\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chdir(t, dir)
+
+	if err := Check("valid.env", "imports.env"); err != nil {
+		t.Errorf("Expected valid files to pass, got %v", err)
+	}
+
+	for filename, line := range map[string]int{"duplicate.env": 3, "invalid.env": 2, "strict_key.env": 2} {
+		err := Check("valid.env", filename, "invalid.env")
+		var fileErr *FileError
+		var parseErr *ParseError
+		if !errors.As(err, &fileErr) || fileErr.Filename != filename || !errors.As(err, &parseErr) || parseErr.Line != line {
+			t.Errorf("Expected %s to fail on line %d, got %v", filename, line, err)
+		}
+	}
+
+	if err := Check("missing.env"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected a missing file to fail, got %v", err)
+	}
+}
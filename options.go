@@ -0,0 +1,686 @@
+package godotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseOption configures how an env file is parsed.
+type ParseOption func(*parseOptions)
+
+// MarshalOption configures how an environment is serialized.
+type MarshalOption func(*marshalOptions)
+
+// LoadOption configures how the values of env files are applied to the environment.
+type LoadOption func(*loadOptions)
+
+// ExecOption configures the environment commands are executed with.
+type ExecOption func(*execOptions)
+
+type expansionMode int
+
+const (
+	// expandAll expands both $VAR and ${VAR} references.
+	expandAll expansionMode = iota
+	// expandBraces only expands ${VAR} references.
+	expandBraces
+	// expandNone leaves every reference as a literal.
+	expandNone
+)
+
+type parseOptions struct {
+	expansion          expansionMode
+	resolver           func(key string) (string, bool)
+	disallowDuplicates bool
+	separator          rune
+	onSkip             func(line int, content string)
+	preserveWhitespace bool
+	expandFromEnv      bool
+	fileRefs           bool
+	fileRefsDir        string
+	strictKeys         bool
+	strictExpansion    bool
+	commentChars       string
+	rawQuotes          bool
+	base64Values       bool
+	// importFile is set when reading files, to read the files they import.
+	importFile func(name string) ([]Pair, error)
+	// onReference is set by parseBytes to track the variables each statement
+	// refers to, and whether they could be resolved.
+	onReference func(key string, found bool)
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// lookup resolves key for expansion, trying the custom resolver before the
+// variables already declared in the file, and then the environment when enabled.
+func (o parseOptions) lookup(key string, vars map[string]string) (string, bool) {
+	if o.resolver != nil {
+		if value, ok := o.resolver(key); ok {
+			return value, true
+		}
+	}
+	value, ok := vars[key]
+	if !ok && o.expandFromEnv {
+		return os.LookupEnv(key)
+	}
+	return value, ok
+}
+
+// isSeparator reports whether c ends a key name.
+func (o parseOptions) isSeparator(c rune) bool {
+	if o.separator != 0 {
+		return c == o.separator
+	}
+	// library also supports yaml-style value declaration
+	return c == '=' || c == ':'
+}
+
+// isComment reports whether c starts a comment.
+func (o parseOptions) isComment(c rune) bool {
+	if o.commentChars != "" {
+		return strings.ContainsRune(o.commentChars, c)
+	}
+	return c == charComment
+}
+
+// DisableExpansion turns off variable expansion, so values such as
+// PASSWORD=$uper$ecret are kept as written.
+//
+// Escaped dollar signs (\$) in double quoted values are still unescaped.
+func DisableExpansion() ParseOption {
+	return func(o *parseOptions) {
+		o.expansion = expandNone
+	}
+}
+
+// BraceOnlyExpansion only expands references written as ${VAR}, while bare
+// $VAR references are kept as written.
+func BraceOnlyExpansion() ParseOption {
+	return func(o *parseOptions) {
+		o.expansion = expandBraces
+	}
+}
+
+// ExpandWith resolves expanded variables through lookup, falling back to the
+// variables already declared in the file when it reports a key as missing.
+//
+// This allows references such as ${VAULT_DB_PASSWORD} to be filled in from a
+// secrets manager, or any other source, at parse time.
+func ExpandWith(lookup func(key string) (string, bool)) ParseOption {
+	return func(o *parseOptions) {
+		o.resolver = lookup
+	}
+}
+
+// DisallowDuplicates makes parsing fail when a key is declared more than once,
+// rather than keeping the last declared value. Keys imported with @import can
+// still be declared again, to override them.
+func DisallowDuplicates() ParseOption {
+	return func(o *parseOptions) {
+		o.disallowDuplicates = true
+	}
+}
+
+// Separator makes keys and values split on sep alone, rather than on either = or
+// the yaml-style :. It must be an ASCII character other than a newline or #.
+func Separator(sep rune) ParseOption {
+	return func(o *parseOptions) {
+		o.separator = sep
+	}
+}
+
+// CommentChars makes comments start with any of chars rather than with # alone,
+// such as "#;" for INI-flavored files also using ; for comments. As with #, a
+// comment following a value must be preceded by whitespace.
+func CommentChars(chars string) ParseOption {
+	return func(o *parseOptions) {
+		o.commentChars = chars
+	}
+}
+
+// OnSkip makes malformed lines be skipped rather than failing the parse, with fn
+// called for each of them with its 1-based line number and raw content.
+//
+// This allows surfacing typos such as "DATABASE URL=foo" as warnings while still
+// reading the rest of the file. Such names holding whitespace are otherwise read
+// as they are, unless StrictKeys is set.
+func OnSkip(fn func(line int, content string)) ParseOption {
+	return func(o *parseOptions) {
+		o.onSkip = fn
+	}
+}
+
+// PreserveWhitespace keeps the leading and trailing whitespace of unquoted values,
+// which are trimmed by default, so that KEY=  padded  is read as "  padded  ".
+//
+// The whitespace around the separator is part of the value as well, while that
+// before an inline comment is still left out. Quoted values are unaffected.
+func PreserveWhitespace() ParseOption {
+	return func(o *parseOptions) {
+		o.preserveWhitespace = true
+	}
+}
+
+// RawQuotes makes quotes part of the values, which are all read as unquoted ones,
+// up to the end of the line or an inline comment. KEY="quoted" then gives the
+// value "quoted", quotes included, and a quoted value can't span several lines.
+func RawQuotes() ParseOption {
+	return func(o *parseOptions) {
+		o.rawQuotes = true
+	}
+}
+
+// StrictKeys makes parsing fail on keys that don't match [A-Za-z_][A-Za-z0-9_]*,
+// such as DB.HOST or 2FA_SECRET, which some shells and programs can't read from
+// the environment. Normalize turns such keys into conforming ones.
+func StrictKeys() ParseOption {
+	return func(o *parseOptions) {
+		o.strictKeys = true
+	}
+}
+
+// StrictExpansion makes parsing fail on references to variables that are
+// neither declared earlier nor found by the other expansion options, which would
+// otherwise expand to empty strings. References with a fallback, such as
+// ${PORT:-8080}, are still allowed.
+func StrictExpansion() ParseOption {
+	return func(o *parseOptions) {
+		o.strictExpansion = true
+	}
+}
+
+// EnableFileRefs makes unquoted values starting with @file: be replaced by the
+// content of the file they name, relative to baseDir unless absolute, keeping
+// large values such as certificates out of the env file:
+//
+//	CERT=@file:tls/cert.pem
+//
+// As in a shell command substitution, the trailing newlines of the file are left
+// out. Parsing fails when a referenced file can't be read, and quoting a value
+// keeps it as written.
+func EnableFileRefs(baseDir string) ParseOption {
+	return func(o *parseOptions) {
+		o.fileRefs = true
+		o.fileRefsDir = baseDir
+	}
+}
+
+// DecodeBase64Values makes unquoted values starting with base64: be replaced by
+// the base64 decoding of the rest of the value, for secrets holding characters
+// that are awkward to write in an env file:
+//
+//	SECRET=base64:aGVsbG8=
+//
+// Parsing fails, naming the key, on values that aren't valid standard base64.
+// Quoting a value keeps it as written.
+func DecodeBase64Values() ParseOption {
+	return func(o *parseOptions) {
+		o.base64Values = true
+	}
+}
+
+type marshalOptions struct {
+	minimalQuoting    bool
+	multilineBlocks   bool
+	separator         string
+	escape            func(value string) string
+	export            bool
+	shellQuoting      bool
+	noTrailingNewline bool
+	// comments is set by MarshalWithComments.
+	comments map[string]string
+}
+
+func newMarshalOptions(opts []MarshalOption) marshalOptions {
+	o := marshalOptions{separator: "=", escape: DoubleQuoteEscape}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// MinimalQuoting only quotes values that need it, so NAME=godotenv is written as
+// is rather than as NAME="godotenv".
+//
+// Values made of anything but letters, digits and _-.,:/@+%=~ are still quoted
+// and escaped.
+func MinimalQuoting() MarshalOption {
+	return func(o *marshalOptions) {
+		o.minimalQuoting = true
+	}
+}
+
+// MultilineBlocks writes values spanning several lines as """ delimited blocks
+// holding the real newlines, rather than as double quoted values with escaped ones:
+//
+//	KEY="""
+//	first line
+//	second line"""
+//
+// Values that contain """ or a carriage return, or that end in ", are still
+// written double quoted.
+func MultilineBlocks() MarshalOption {
+	return func(o *marshalOptions) {
+		o.multilineBlocks = true
+	}
+}
+
+// MarshalSeparator writes sep between keys and values instead of =, such as ": "
+// for files read with Separator(':').
+func MarshalSeparator(sep string) MarshalOption {
+	return func(o *marshalOptions) {
+		o.separator = sep
+	}
+}
+
+// ExportPrefix starts every line with the export keyword, so that the written
+// file can also be sourced by a shell to export its variables. The prefix is
+// ignored when the file is read back.
+func ExportPrefix() MarshalOption {
+	return func(o *marshalOptions) {
+		o.export = true
+	}
+}
+
+// ShellQuoting writes every value between single quotes, the way a shell would
+// read it literally, with single quotes escaped outside of them:
+//
+//	KEY='it'\''s'
+//
+// Combined with ExportPrefix, this makes files that can be sourced by a shell, see
+// MarshalExport. Values holding carriage returns can't be read back as they are,
+// and fail to be marshaled.
+func ShellQuoting() MarshalOption {
+	return func(o *marshalOptions) {
+		o.shellQuoting = true
+	}
+}
+
+// EscapeWith escapes double quoted values with escape rather than DoubleQuoteEscape,
+// so that files can be read by other dotenv implementations with stricter rules:
+//
+//	godotenv.MarshalWithOptions(envMap, godotenv.EscapeWith(func(value string) string {
+//		return strings.Replace(godotenv.DoubleQuoteEscape(value), "\t", `\t`, -1)
+//	}))
+//
+// Values escaped differently may not be read back as they were by this package.
+func EscapeWith(escape func(value string) string) MarshalOption {
+	return func(o *marshalOptions) {
+		o.escape = escape
+	}
+}
+
+// NoTrailingNewline leaves out the newline ending the last line written by Write,
+// WriteOrdered or MarshalTo, for consumers reading it as an extra empty entry.
+func NoTrailingNewline() MarshalOption {
+	return func(o *marshalOptions) {
+		o.noTrailingNewline = true
+	}
+}
+
+type loadOptions struct {
+	filter        func(key string) bool
+	stripPrefix   string
+	caseFold      bool
+	precedence    PrecedenceRule
+	optional      bool
+	expandFromEnv bool
+	onFileLoaded  func(filename string, count int)
+	onKeySet      func(key string)
+	onError       func(filename string, err error)
+	required      []string
+	omitEmpty     bool
+	keepNewline   bool
+	skipEmpty     bool
+	transformer   ValueTransformer
+	parseOptions  []ParseOption
+	overrides     map[string]string
+	// glob matches the filenames that are glob patterns, relative to the working
+	// directory when nil.
+	glob globFunc
+	// strict is set by the loads and reads the options are used for.
+	strict bool
+}
+
+func newLoadOptions(opts []LoadOption) loadOptions {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// globFunc returns the globFunc matching the filenames given as patterns.
+func (o loadOptions) globFunc() globFunc {
+	if o.glob == nil {
+		return globFrom("./")
+	}
+	return o.glob
+}
+
+// transform returns the pairs to apply to the environment once the options are applied.
+func (o loadOptions) transform(pairs []Pair) []Pair {
+	kept := make([]Pair, 0, len(pairs))
+	for _, pair := range pairs {
+		if o.filter != nil && !o.filter(pair.Key) {
+			continue
+		}
+		if o.stripPrefix != "" && strings.HasPrefix(pair.Key, o.stripPrefix) {
+			pair.Key = strings.TrimPrefix(pair.Key, o.stripPrefix)
+			if pair.Key == "" || strings.Contains(pair.Key, "=") {
+				continue
+			}
+		}
+		if o.caseFold {
+			pair.Key = strings.ToUpper(pair.Key)
+		}
+		kept = append(kept, pair)
+	}
+	// rewritten keys may now collide, the last declared value wins as usual
+	return mergePairs(nil, kept)
+}
+
+// apply returns the pairs to load once the options are applied.
+func (o loadOptions) apply(pairs []Pair) ([]Pair, error) {
+	pairs = o.transform(pairs)
+	if o.transformer != nil {
+		for i, pair := range pairs {
+			value, err := o.transformer(pair.Key, pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pair.Key, err)
+			}
+			pairs[i].Value = value
+		}
+	}
+	return o.checkEmpty(pairs)
+}
+
+// checkEmpty returns pairs without their empty values when OmitEmpty is set. In
+// strict mode, a key given to DisallowEmpty that is declared empty is an error.
+func (o loadOptions) checkEmpty(pairs []Pair) ([]Pair, error) {
+	if o.strict {
+		for _, pair := range pairs {
+			if pair.Value == "" && containsString(o.required, pair.Key) {
+				return nil, fmt.Errorf("%s is declared with an empty value", pair.Key)
+			}
+		}
+	}
+	if !o.omitEmpty {
+		return pairs, nil
+	}
+
+	kept := pairs[:0:0]
+	for _, pair := range pairs {
+		if pair.Value != "" {
+			kept = append(kept, pair)
+		}
+	}
+	return kept, nil
+}
+
+// precedenceRule returns the rule deciding whether file values replace the set
+// variables, which defaults to overload.
+func (o loadOptions) precedenceRule(overload bool) PrecedenceRule {
+	rule := EnvWins
+	if o.precedence != nil {
+		rule = o.precedence
+	} else if overload {
+		rule = FileWins
+	}
+	if !o.skipEmpty {
+		return rule
+	}
+	return func(key, fileVal, envVal string) bool {
+		if fileVal == "" && envVal != "" {
+			return false
+		}
+		return rule(key, fileVal, envVal)
+	}
+}
+
+// ValueTransformer returns the value to load for key in place of value, the one
+// read from an env file.
+type ValueTransformer func(key, value string) (string, error)
+
+// TransformValues runs the values read from the env files through fn before they
+// are loaded, such as to resolve placeholders or decrypt them one by one:
+//
+//	err := godotenv.LoadWithOptions(true, nil, godotenv.TransformValues(func(key, value string) (string, error) {
+//		return strings.TrimSpace(value), nil
+//	}))
+//
+// An error returned by fn fails the file, which aborts strict loads and is
+// skipped otherwise.
+func TransformValues(fn ValueTransformer) LoadOption {
+	return func(o *loadOptions) {
+		o.transformer = fn
+	}
+}
+
+// Filter only loads the keys for which keep returns true, the others being
+// neither set nor overridden.
+func Filter(keep func(key string) bool) LoadOption {
+	return func(o *loadOptions) {
+		o.filter = keep
+	}
+}
+
+// StripPrefix removes prefix from the keys starting with it before they are
+// loaded, so that APP_PORT is set as PORT. Keys that are left empty once the
+// prefix is removed are skipped.
+//
+// Keys without the prefix are loaded as they are, combine it with Filter to skip them:
+//
+//	godotenv.LoadWithOptions(true, nil, godotenv.Filter(godotenv.WithPrefix("APP_")), godotenv.StripPrefix("APP_"))
+func StripPrefix(prefix string) LoadOption {
+	return func(o *loadOptions) {
+		o.stripPrefix = prefix
+	}
+}
+
+// CaseFold uppercases keys before they are loaded, matching the case insensitive
+// semantics of the Windows environment on every platform.
+func CaseFold() LoadOption {
+	return func(o *loadOptions) {
+		o.caseFold = true
+	}
+}
+
+// parseOpts returns the options the env files are parsed with.
+func (o loadOptions) parseOpts() []ParseOption {
+	if !o.expandFromEnv {
+		return o.parseOptions
+	}
+	return append(o.parseOptions[:len(o.parseOptions):len(o.parseOptions)], func(po *parseOptions) {
+		po.expandFromEnv = true
+	})
+}
+
+// DisallowEmpty makes strict loads and reads fail on the files declaring any of
+// keys with an empty value, such as KEY=. Keys that aren't declared at all are
+// left to Require.
+func DisallowEmpty(keys ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.required = append(o.required, keys...)
+	}
+}
+
+// OmitEmpty skips the keys declared with an empty value, as if they weren't
+// declared, instead of setting or returning them as empty strings.
+func OmitEmpty() LoadOption {
+	return func(o *loadOptions) {
+		o.omitEmpty = true
+	}
+}
+
+// SkipEmptyOnOverload keeps the variables that are set to a non-empty value
+// when the files declare them empty, such as with LOG_LEVEL=, even when
+// overloading, so that a file can't blank them by accident.
+//
+//	err := godotenv.OverloadWithOptions(true, nil, godotenv.SkipEmptyOnOverload())
+func SkipEmptyOnOverload() LoadOption {
+	return func(o *loadOptions) {
+		o.skipEmpty = true
+	}
+}
+
+// KeepTrailingNewline keeps the trailing newline of the files read by
+// LoadSecretsDir, which is otherwise left out.
+func KeepTrailingNewline() LoadOption {
+	return func(o *loadOptions) {
+		o.keepNewline = true
+	}
+}
+
+// ExpandFromEnv makes references to variables that aren't declared earlier in the
+// file, such as ${HOME}, expand to their value in the environment:
+//
+//	godotenv.LoadWithOptions(true, nil, godotenv.ExpandFromEnv())
+//
+// By default references are only expanded from the file itself.
+func ExpandFromEnv() LoadOption {
+	return func(o *loadOptions) {
+		o.expandFromEnv = true
+	}
+}
+
+// WithOverrides sets the values of overrides into the environment once the files
+// are loaded without error, whatever the files and the environment hold, as
+// LoadWithOverrides does. They are reported in Result.Overridden, and ignored by
+// reads.
+func WithOverrides(overrides map[string]string) LoadOption {
+	return func(o *loadOptions) {
+		o.overrides = overrides
+	}
+}
+
+// WithParseOptions parses the env files with the given options, such as
+// DecodeBase64Values or StrictExpansion:
+//
+//	err := godotenv.LoadWithOptions(true, nil, godotenv.WithParseOptions(godotenv.DecodeBase64Values()))
+func WithParseOptions(opts ...ParseOption) LoadOption {
+	return func(o *loadOptions) {
+		o.parseOptions = append(o.parseOptions, opts...)
+	}
+}
+
+// OnFileLoaded calls fn after each env file is loaded, with the number of keys it
+// declares once the other options are applied, such as to report metrics.
+func OnFileLoaded(fn func(filename string, count int)) LoadOption {
+	return func(o *loadOptions) {
+		o.onFileLoaded = fn
+	}
+}
+
+// OnKeySet calls fn for each key set into the environment, after the file
+// declaring it is applied. Keys skipped because they are already set aren't
+// passed to fn.
+func OnKeySet(fn func(key string)) LoadOption {
+	return func(o *loadOptions) {
+		o.onKeySet = fn
+	}
+}
+
+// OnError calls fn for each env file that fails to be loaded, including the files
+// skipped in non-strict mode.
+func OnError(fn func(filename string, err error)) LoadOption {
+	return func(o *loadOptions) {
+		o.onError = fn
+	}
+}
+
+// StrictParseOnly makes strict loads skip the files that don't exist, while still
+// failing on the first malformed one, for files that are optional but must be
+// valid when present:
+//
+//	godotenv.LoadWithOptions(true, []string{".env", ".env.local"}, godotenv.StrictParseOnly())
+//
+// An error is still returned when none of the files exist.
+func StrictParseOnly() LoadOption {
+	return func(o *loadOptions) {
+		o.optional = true
+	}
+}
+
+type execOptions struct {
+	isolated bool
+	inherit  []string
+	lists    map[string]listVar
+}
+
+// listVar describes how the file value of a list variable, such as PATH, is merged
+// with its inherited value.
+type listVar struct {
+	separator string
+	prepend   bool
+}
+
+// merge returns the list made of value and inherited, in the configured order.
+func (l listVar) merge(value, inherited string) string {
+	if inherited == "" {
+		return value
+	}
+	if l.prepend {
+		return value + l.separator + inherited
+	}
+	return inherited + l.separator + value
+}
+
+func newExecOptions(opts []ExecOption) execOptions {
+	var o execOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// IsolatedEnv runs the command with the variables of the env files alone, rather
+// than with the whole environment of the current process, which is left untouched.
+//
+// The variables named by inherit, such as PATH or HOME, are passed on to the
+// command when they are set. Unless overloading, they take precedence over the
+// values of the files as they would with Load.
+func IsolatedEnv(inherit ...string) ExecOption {
+	return func(o *execOptions) {
+		o.isolated = true
+		o.inherit = inherit
+	}
+}
+
+// PrependList makes the values the env files declare for keys, such as PATH or
+// PYTHONPATH, be prepended to their inherited values, joined by sep, rather than
+// taking their place:
+//
+//	godotenv.ExecWithOptions(ctx, nil, "make", nil, true, false,
+//		godotenv.PrependList(string(os.PathListSeparator), "PATH"))
+//
+// With PATH=./bin in the file, the command is run with ./bin:$PATH while the
+// current process keeps its PATH.
+func PrependList(sep string, keys ...string) ExecOption {
+	return listOption(listVar{separator: sep, prepend: true}, keys)
+}
+
+// AppendList is like PrependList, but the values of the env files are appended
+// to the inherited values.
+func AppendList(sep string, keys ...string) ExecOption {
+	return listOption(listVar{separator: sep}, keys)
+}
+
+func listOption(list listVar, keys []string) ExecOption {
+	return func(o *execOptions) {
+		if o.lists == nil {
+			o.lists = make(map[string]listVar)
+		}
+		for _, key := range keys {
+			o.lists[key] = list
+		}
+	}
+}
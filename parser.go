@@ -2,11 +2,15 @@ package godotenv
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -17,44 +21,470 @@ const (
 	exportPrefix = "export"
 )
 
-func parseBytes(src []byte, out map[string]string) error {
-	src = bytes.Replace(src, []byte("\r\n"), []byte("\n"), -1)
+// maxExpansionDepth caps how long a chain of variables referring to one another can be.
+const maxExpansionDepth = 64
+
+// blockDelimiter returns the delimiter of the value captured verbatim, real
+// newlines included, that src starts with, or nil if it starts with none.
+//
+// A backtick only opens such a block when a newline directly follows it, so that
+// unquoted values merely starting with one, such as `cmd`, are read as they are.
+func blockDelimiter(src []byte) []byte {
+	switch {
+	case bytes.HasPrefix(src, []byte(`"""`)):
+		return []byte(`"""`)
+	case bytes.HasPrefix(src, []byte("`\n")):
+		return []byte("`")
+	}
+	return nil
+}
+
+// ParseError reports a malformed statement in an env file.
+type ParseError struct {
+	// Line is the 1-based line number the statement starts on.
+	Line int
+	// Content is the raw content of that line.
+	Content string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %q: %v", e.Line, e.Content, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseBytes parses src and returns its assignments in file order.
+//
+// A key declared more than once keeps the position of its first
+// declaration and the value of its last one.
+func parseBytes(src []byte, opts parseOptions) ([]Pair, error) {
+	src = normalizeSource(src)
+	index := make(map[string]int)
+	var statements [][]byte
+	var pairs []Pair
+
+	err := parseFunc(src, opts, func(key, value string, statement []byte) error {
+		if i, ok := index[key]; ok {
+			imported := bytes.HasPrefix(statements[i], []byte(importDirective))
+			if opts.disallowDuplicates && !imported && !bytes.HasPrefix(statement, []byte(importDirective)) {
+				return newParseError(src, statement, fmt.Errorf(
+					"duplicate key %q, first declared on line %d", key, lineNumber(src, statements[i])))
+			}
+			if imported {
+				statements[i] = statement
+			}
+			pairs[i].Value = value
+			return nil
+		}
+		index[key] = len(pairs)
+		statements = append(statements, statement)
+		pairs = append(pairs, Pair{Key: key, Value: value})
+		return nil
+	})
+	return pairs, err
+}
+
+// parseFunc parses src, which must already be normalized, and calls fn with each
+// assignment and the statement it starts, in file order and duplicates included.
+func parseFunc(src []byte, opts parseOptions, fn func(key, value string, statement []byte) error) error {
+	vars := make(map[string]string)
+	refs := newReferenceGraph()
+	var current []reference
+	opts.onReference = func(key string, found bool) {
+		_, declared := vars[key]
+		current = append(current, reference{key: key, resolved: found && declared, missing: !found})
+	}
+
 	cutset := src
 	for {
-		cutset = getStatementStart(cutset)
+		cutset = getStatementStart(cutset, opts)
 		if cutset == nil {
 			// reached end of file
 			break
 		}
 
-		key, left, err := locateKeyName(cutset)
+		if name, left, ok := importStatement(cutset, opts); ok && opts.importFile != nil {
+			pairs, err := opts.importFile(name)
+			if err != nil {
+				return newParseError(src, cutset, err)
+			}
+			for _, pair := range pairs {
+				if err := refs.add(pair.Key, nil, cutset); err != nil {
+					return newParseError(src, cutset, err)
+				}
+				if err := fn(pair.Key, pair.Value, cutset); err != nil {
+					return err
+				}
+				vars[pair.Key] = pair.Value
+			}
+			cutset = left
+			continue
+		}
+
+		current = current[:0]
+		key, value, left, err := parseStatement(cutset, vars, opts)
+		if err != nil && opts.onSkip != nil {
+			parseErr := newParseError(src, cutset, err)
+			opts.onSkip(parseErr.Line, parseErr.Content)
+			cutset = skipLine(cutset)
+			continue
+		}
+		if err == nil && opts.strictExpansion {
+			err = undefinedReference(current)
+		}
+		if err == nil && opts.fileRefs {
+			value, err = readFileRef(value, cutset, opts)
+		}
+		if err == nil && opts.base64Values {
+			value, err = decodeBase64Value(key, value, cutset, opts)
+		}
 		if err != nil {
+			return newParseError(src, cutset, err)
+		}
+
+		if err := refs.add(key, current, cutset); err != nil {
+			return newParseError(src, cutset, err)
+		}
+		if err := fn(key, value, cutset); err != nil {
 			return err
 		}
+		vars[key] = value
+		cutset = left
+	}
+
+	if statement, err := refs.findCycle(); err != nil {
+		return newParseError(src, statement, err)
+	}
+	return nil
+}
+
+// importDirective starts the lines importing the keys of another env file.
+const importDirective = "@import"
+
+// maxImportDepth caps how deep env files can import one another.
+const maxImportDepth = 16
+
+// importStatement reports whether src starts with an import directive, returning
+// the name of the imported file and the rest of src.
+func importStatement(src []byte, opts parseOptions) (name string, rest []byte, ok bool) {
+	if !bytes.HasPrefix(src, []byte(importDirective)) {
+		return "", nil, false
+	}
+	line := src[len(importDirective):]
+	end := bytes.IndexByte(line, '\n')
+	if end == -1 {
+		end = len(line)
+	}
+	if end == 0 || !isSpace(rune(line[0])) {
+		return "", nil, false
+	}
+	name = string(line[:end])
+	// as for unquoted values, an inline comment starts at a # preceded by whitespace
+	for i := 1; i < len(name); i++ {
+		if opts.isComment(rune(name[i])) && isSpace(rune(name[i-1])) {
+			name = name[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(name), line[end:], true
+}
+
+// fileRefPrefix starts the values replaced by the content of a file when
+// EnableFileRefs is set.
+const fileRefPrefix = "@file:"
+
+// readFileRef returns the content of the file value refers to if it is an
+// unquoted file reference, or value as it is otherwise.
+func readFileRef(value string, statement []byte, opts parseOptions) (string, error) {
+	if !strings.HasPrefix(value, fileRefPrefix) || quoteStyle(statement, opts) != Unquoted {
+		return value, nil
+	}
 
-		value, left, err := extractVarValue(left, out)
+	name := strings.TrimPrefix(value, fileRefPrefix)
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(opts.fileRefsDir, name)
+	}
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("reading referenced file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// base64Prefix starts the values decoded from base64 when DecodeBase64Values is
+// set.
+const base64Prefix = "base64:"
+
+// decodeBase64Value returns the decoded value of key if it is an unquoted base64
+// value, or value as it is otherwise.
+func decodeBase64Value(key, value string, statement []byte, opts parseOptions) (string, error) {
+	if !strings.HasPrefix(value, base64Prefix) || quoteStyle(statement, opts) != Unquoted {
+		return value, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, base64Prefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 value for %s: %w", key, err)
+	}
+	return string(decoded), nil
+}
+
+// reference is a variable referred to by a statement.
+type reference struct {
+	key string
+	// resolved is set when the variable was expanded from an earlier statement.
+	resolved bool
+	// missing is set when the variable couldn't be expanded at all.
+	missing bool
+}
+
+// undefinedReference returns an error naming the first of refs that couldn't be
+// expanded, if any.
+func undefinedReference(refs []reference) error {
+	for _, ref := range refs {
+		if ref.missing {
+			return fmt.Errorf("undefined variable %q", ref.key)
+		}
+	}
+	return nil
+}
+
+// forwardReference is a reference to a variable that wasn't declared yet.
+type forwardReference struct {
+	from, to  string
+	statement []byte
+}
+
+// referenceGraph tracks the references between the variables of a file, to
+// report the cycles that sequential expansion would silently resolve to empty
+// strings, such as A=${B} followed by B=${A}.
+type referenceGraph struct {
+	edges   map[string][]string
+	depth   map[string]int
+	forward []forwardReference
+}
+
+func newReferenceGraph() *referenceGraph {
+	return &referenceGraph{
+		edges: make(map[string][]string),
+		depth: make(map[string]int),
+	}
+}
+
+// add records the references of the statement declaring key.
+func (g *referenceGraph) add(key string, refs []reference, statement []byte) error {
+	depth := 0
+	for _, ref := range refs {
+		g.edges[key] = append(g.edges[key], ref.key)
+		if ref.resolved && g.depth[ref.key]+1 > depth {
+			depth = g.depth[ref.key] + 1
+		}
+		// a variable referring to itself, as in PATH=$PATH:/bin, means its
+		// previous value rather than a cycle
+		if ref.missing && ref.key != key {
+			g.forward = append(g.forward, forwardReference{from: key, to: ref.key, statement: statement})
+		}
+	}
+
+	if depth > maxExpansionDepth {
+		return fmt.Errorf("variable %q is expanded through more than %d nested references", key, maxExpansionDepth)
+	}
+	g.depth[key] = depth
+	return nil
+}
+
+// findCycle returns an error and the statement it starts on if a variable refers,
+// before it is declared, to a variable that refers back to it.
+func (g *referenceGraph) findCycle() ([]byte, error) {
+	for _, ref := range g.forward {
+		// every declared variable has a depth
+		if _, declared := g.depth[ref.to]; !declared {
+			continue
+		}
+		if path := g.path(ref.to, ref.from); path != nil {
+			cycle := append([]string{ref.from}, path...)
+			return ref.statement, fmt.Errorf("cyclic variable reference %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return nil, nil
+}
+
+// path returns the keys leading from one key to another through references, both
+// included, or nil if there is no such path.
+func (g *referenceGraph) path(from, to string) []string {
+	parents := map[string]string{from: from}
+	queue := []string{from}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if key == to {
+			path := []string{key}
+			for key != from {
+				key = parents[key]
+				path = append([]string{key}, path...)
+			}
+			return path
+		}
+		for _, next := range g.edges[key] {
+			if _, seen := parents[next]; !seen {
+				parents[next] = key
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// rawStatement locates an assignment, or a comment line, of an env file.
+type rawStatement struct {
+	// key is empty for comment lines.
+	key string
+	// start, valueStart and end are offsets into the source, valueStart being
+	// that of the raw value, quotes included, of assignments.
+	start, valueStart, end int
+}
+
+// scanStatements locates the assignments and comment lines of src in order,
+// without expanding their values.
+func scanStatements(src []byte, opts parseOptions) ([]rawStatement, error) {
+	opts.expansion = expandNone
+	opts.onReference = nil
+	var statements []rawStatement
+
+	cutset := bytes.TrimPrefix(src, utf8BOM)
+	for {
+		pos := indexOfNonSpaceChar(cutset)
+		if pos == -1 {
+			return statements, nil
+		}
+		cutset = cutset[pos:]
+		start := len(src) - len(cutset)
+
+		if _, left, ok := importStatement(cutset, opts); ok {
+			end := len(src) - len(left)
+			statements = append(statements, rawStatement{start: start, end: end})
+			cutset = left
+			continue
+		}
+		if opts.isComment(rune(cutset[0])) {
+			end := bytes.IndexByte(cutset, '\n')
+			if end == -1 {
+				end = len(cutset)
+			}
+			statements = append(statements, rawStatement{start: start, end: start + end})
+			cutset = cutset[end:]
+			continue
+		}
+
+		key, rest, err := locateKeyName(cutset, opts)
+		var left []byte
+		if err == nil {
+			_, left, err = extractVarValue(rest, nil, opts)
+		}
+		if err != nil && opts.onSkip != nil {
+			cutset = skipLine(cutset)
+			continue
+		}
 		if err != nil {
-			return err
+			return nil, newParseError(src, cutset, err)
 		}
 
-		out[key] = value
+		statements = append(statements, rawStatement{
+			key:        key,
+			start:      start,
+			valueStart: len(src) - len(rest),
+			end:        len(src) - len(left),
+		})
 		cutset = left
 	}
+}
 
-	return nil
+// utf8BOM is the byte order mark some Windows editors start UTF-8 files with.
+var utf8BOM = []byte("\ufeff")
+
+// normalizeSource strips the byte order mark of src and turns its \r\n and \r
+// line endings into \n. src is left untouched, a copy being made if needed.
+func normalizeSource(src []byte) []byte {
+	src = bytes.TrimPrefix(src, utf8BOM)
+	if bytes.IndexByte(src, '\r') == -1 {
+		return src
+	}
+	src = bytes.Replace(src, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(src, []byte("\r"), []byte("\n"), -1)
+}
+
+// parseStatement parses the assignment at the start of src and returns the rest of the slice.
+func parseStatement(src []byte, vars map[string]string, opts parseOptions) (key, value string, rest []byte, err error) {
+	key, rest, err = locateKeyName(src, opts)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if opts.strictKeys && !isPortableKey(key) {
+		return "", "", nil, fmt.Errorf("invalid variable name %q, expected letters, digits and _ not starting with a digit", key)
+	}
+
+	value, rest, err = extractVarValue(rest, vars, opts)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return key, value, rest, nil
+}
+
+// skipLine returns src past its first line break, or nil if it has none.
+func skipLine(src []byte) []byte {
+	pos := bytes.IndexByte(src, '\n')
+	if pos == -1 {
+		return nil
+	}
+	return src[pos+1:]
+}
+
+// newParseError wraps err with the position of statement, which must be a subslice of src.
+func newParseError(src, statement []byte, err error) *ParseError {
+	offset := len(src) - len(statement)
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := bytes.IndexByte(src[offset:], '\n')
+	if end == -1 {
+		end = len(src)
+	} else {
+		end += offset
+	}
+
+	return &ParseError{
+		Line:    lineNumber(src, statement),
+		Content: redactLine(src[start:end]),
+		Err:     err,
+	}
+}
+
+// redactLine returns line, with its value replaced by *** if it declares a secret key.
+func redactLine(line []byte) string {
+	key, rest, err := locateKeyName(line, parseOptions{})
+	if err != nil || !isSecret(key) {
+		return string(line)
+	}
+	return string(line[:len(line)-len(rest)]) + RedactedValue
+}
+
+// lineNumber returns the 1-based line on which statement, a subslice of src, starts.
+func lineNumber(src, statement []byte) int {
+	return bytes.Count(src[:len(src)-len(statement)], []byte("\n")) + 1
 }
 
 // getStatementPosition returns position of statement begin.
 //
 // It skips any comment line or non-whitespace character.
-func getStatementStart(src []byte) []byte {
+func getStatementStart(src []byte, opts parseOptions) []byte {
 	pos := indexOfNonSpaceChar(src)
 	if pos == -1 {
 		return nil
 	}
 
 	src = src[pos:]
-	if src[0] != charComment {
+	if !opts.isComment(rune(src[0])) {
 		return src
 	}
 
@@ -64,11 +494,11 @@ func getStatementStart(src []byte) []byte {
 		return nil
 	}
 
-	return getStatementStart(src[pos:])
+	return getStatementStart(src[pos:], opts)
 }
 
 // locateKeyName locates and parses key name and returns rest of slice
-func locateKeyName(src []byte) (key string, cutset []byte, err error) {
+func locateKeyName(src []byte, opts parseOptions) (key string, cutset []byte, err error) {
 	// trim "export" and space at beginning
 	src = bytes.TrimLeftFunc(src, isSpace)
 	if bytes.HasPrefix(src, []byte(exportPrefix)) {
@@ -80,19 +510,26 @@ func locateKeyName(src []byte) (key string, cutset []byte, err error) {
 
 	// locate key name end and validate it in single loop
 	offset := 0
+	spaced := false
 loop:
 	for i, char := range src {
 		rchar := rune(char)
+		if opts.isSeparator(rchar) {
+			key = string(src[0:i])
+			offset = i + 1
+			break loop
+		}
 		if isSpace(rchar) {
+			spaced = true
 			continue
 		}
+		// whitespace within a name, such as KEY NAME=x, is kept by default as it
+		// always was, and only reported as malformed to the callers asking for it
+		if spaced && (opts.onSkip != nil || opts.strictKeys) {
+			return "", nil, errors.New("unexpected whitespace in variable name")
+		}
 
 		switch char {
-		case '=', ':':
-			// library also supports yaml-style value declaration
-			key = string(src[0:i])
-			offset = i + 1
-			break loop
 		case '_':
 		default:
 			// variable name should match [A-Za-z0-9_.]
@@ -101,8 +538,7 @@ loop:
 			}
 
 			return "", nil, fmt.Errorf(
-				`unexpected character %q in variable name near %q`,
-				string(char), string(src))
+				`unexpected character %q in variable name`, string(char))
 		}
 	}
 
@@ -113,48 +549,68 @@ loop:
 	// trim whitespace
 	key = strings.TrimRightFunc(key, unicode.IsSpace)
 	cutset = bytes.TrimLeftFunc(src[offset:], isSpace)
+	if opts.preserveWhitespace && !hasValueDelimiter(cutset) {
+		cutset = src[offset:]
+	}
 	return key, cutset, nil
 }
 
+// isPortableKey reports whether key matches [A-Za-z_][A-Za-z0-9_]*, the names
+// every shell and platform handles.
+func isPortableKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // extractVarValue extracts variable value and returns rest of slice
-func extractVarValue(src []byte, vars map[string]string) (value string, rest []byte, err error) {
+func extractVarValue(src []byte, vars map[string]string, opts parseOptions) (value string, rest []byte, err error) {
+	if delimiter := blockDelimiter(src); delimiter != nil && !opts.rawQuotes {
+		return extractMultilineValue(src, delimiter)
+	}
+
 	quote, hasPrefix := hasQuotePrefix(src)
-	if !hasPrefix {
+	if !hasPrefix || opts.rawQuotes {
 		// unquoted value - read until end of line
-		endOfLine := bytes.IndexFunc(src, isLineEnd)
-
-		// Hit EOF without a trailing newline
-		if endOfLine == -1 {
-			endOfLine = len(src)
-
-			if endOfLine == 0 {
-				return "", nil, nil
-			}
+		if len(src) == 0 {
+			return "", nil, nil
 		}
+		joined, rest := continuedLine(src, opts)
 
 		// Convert line to rune away to do accurate countback of runes
-		line := []rune(string(src[0:endOfLine]))
+		line := []rune(joined)
 
 		// Assume end of line is end of var
 		endOfVar := len(line)
 		if endOfVar == 0 {
-			return "", src[endOfLine:], nil
+			return "", rest, nil
 		}
-
-		// Work backwards to check if the line ends in whitespace then
-		// a comment (ie asdasd # some comment)
-		for i := endOfVar - 1; i >= 0; i-- {
-			if line[i] == charComment && i > 0 {
-				if isSpace(line[i-1]) {
-					endOfVar = i
-					break
-				}
-			}
+		if i := inlineComment(line, opts); i != -1 {
+			endOfVar = i
 		}
 
-		trimmed := strings.TrimFunc(string(line[0:endOfVar]), isSpace)
+		trimmed := string(line[0:endOfVar])
+		if !opts.preserveWhitespace {
+			trimmed = strings.TrimFunc(trimmed, isSpace)
+		} else if endOfVar < len(line) {
+			// the whitespace before an inline comment belongs to the comment
+			trimmed = strings.TrimRightFunc(trimmed, isSpace)
+		}
 
-		return expandVariables(trimmed, vars), src[endOfLine:], nil
+		expanded, err := expandVariables(trimmed, vars, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return expanded, rest, nil
 	}
 
 	// lookup quoted string terminator
@@ -163,30 +619,70 @@ func extractVarValue(src []byte, vars map[string]string) (value string, rest []b
 			continue
 		}
 
-		// skip escaped quote symbol (\" or \', depends on quote)
-		if prevChar := src[i-1]; prevChar == '\\' {
+		// skip escaped quote symbol (\" or \', depends on quote), which is preceded
+		// by an odd number of backslashes as \\ is an escaped backslash
+		if isEscaped(src, i) {
 			continue
 		}
 
 		// trim quotes
-		trimFunc := isCharFunc(rune(quote))
-		value = string(bytes.TrimLeftFunc(bytes.TrimRightFunc(src[0:i], trimFunc), trimFunc))
+		value = string(src[1:i])
 		if quote == prefixDoubleQuote {
 			// unescape newlines for double quote (this is compat feature)
 			// and expand environment variables
-			value = expandVariables(expandEscapes(value), vars)
+			if value, err = expandVariables(expandEscapes(value), vars, opts); err != nil {
+				return "", nil, err
+			}
+		}
+		// single quoted values are taken literally, escapes and references included,
+		// but for the \' that don't close them
+		if quote == prefixSingleQuote {
+			return concatSingleQuoted(value, src[i+1:])
 		}
-
 		return value, src[i+1:], nil
 	}
 
-	// return formatted error if quoted string is not terminated
-	valEndIndex := bytes.IndexFunc(src, isCharFunc('\n'))
-	if valEndIndex == -1 {
-		valEndIndex = len(src)
+	return "", nil, errors.New("unterminated quoted value")
+}
+
+// concatSingleQuoted appends to value the backslash escaped characters and single
+// quoted strings directly following it, as a shell would. This reads the values
+// holding single quotes written by ShellQuoting, such as:
+//
+//	'it'\''s'
+func concatSingleQuoted(value string, src []byte) (string, []byte, error) {
+	for len(src) > 0 {
+		switch {
+		case src[0] == '\\' && len(src) > 1 && !isLineEnd(rune(src[1])):
+			r, size := utf8.DecodeRune(src[1:])
+			value += string(r)
+			src = src[1+size:]
+		case src[0] == prefixSingleQuote:
+			next, rest, err := extractVarValue(src, nil, parseOptions{expansion: expandNone})
+			if err != nil {
+				return "", nil, err
+			}
+			return value + next, rest, nil
+		default:
+			return value, src, nil
+		}
+	}
+	return value, src, nil
+}
+
+// extractMultilineValue extracts a value enclosed in delimiter, taking its content verbatim.
+//
+// As in TOML multi-line strings, a newline right after the opening delimiter is dropped
+// so that the content can start on its own line.
+func extractMultilineValue(src, delimiter []byte) (value string, rest []byte, err error) {
+	body := src[len(delimiter):]
+	end := bytes.Index(body, delimiter)
+	if end == -1 {
+		return "", nil, errors.New("unterminated multiline value")
 	}
 
-	return "", nil, fmt.Errorf("unterminated quoted value %s", src[:valEndIndex])
+	value = strings.TrimPrefix(string(body[:end]), "\n")
+	return value, body[end+len(delimiter):], nil
 }
 
 func expandEscapes(str string) string {
@@ -204,6 +700,77 @@ func expandEscapes(str string) string {
 	return unescapeCharsRegex.ReplaceAllString(out, "$1")
 }
 
+// continuedLine returns the unquoted value at the start of src, up to the end
+// of its line, and what follows it. A line ending in an unescaped backslash is
+// continued on the next one: the backslash and the newline are dropped, as a
+// shell would. A backslash right before EOF has nothing to continue and is kept,
+// as is one ending an inline comment or followed by a declaration, such as BAR=b,
+// which is left to be read as such.
+func continuedLine(src []byte, opts parseOptions) (string, []byte) {
+	var line strings.Builder
+	for {
+		endOfLine := bytes.IndexFunc(src, isLineEnd)
+		if endOfLine == -1 {
+			line.Write(src)
+			return line.String(), src[len(src):]
+		}
+		if !isEscaped(src, endOfLine) || inlineComment([]rune(line.String()+string(src[:endOfLine])), opts) != -1 ||
+			isDeclaration(nextLine(src[endOfLine:]), opts) {
+			line.Write(src[:endOfLine])
+			return line.String(), src[endOfLine:]
+		}
+		line.Write(src[:endOfLine-1])
+		src = bytes.TrimPrefix(src[endOfLine:], []byte("\r"))
+		src = bytes.TrimPrefix(src, []byte("\n"))
+	}
+}
+
+// nextLine returns the line following the line end src starts with.
+func nextLine(src []byte) []byte {
+	src = bytes.TrimPrefix(src, []byte("\r"))
+	src = bytes.TrimPrefix(src, []byte("\n"))
+	if end := bytes.IndexFunc(src, isLineEnd); end != -1 {
+		return src[:end]
+	}
+	return src
+}
+
+// isDeclaration reports whether line declares a variable, such as KEY=value.
+func isDeclaration(line []byte, opts parseOptions) bool {
+	key, _, err := locateKeyName(line, opts)
+	return err == nil && key != ""
+}
+
+// inlineComment returns the index of the inline comment of an unquoted value
+// (ie asdasd # some comment), which starts at the first # preceded by whitespace,
+// or -1 if it has none. A # stuck to the value, such as in http://host/#fragment,
+// is part of it.
+func inlineComment(line []rune, opts parseOptions) int {
+	for i := 1; i < len(line); i++ {
+		if opts.isComment(line[i]) && isSpace(line[i-1]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isEscaped reports whether src[i] is preceded by an odd number of backslashes.
+func isEscaped(src []byte, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && src[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// hasValueDelimiter reports whether src starts with a quote or a multiline delimiter.
+func hasValueDelimiter(src []byte) bool {
+	if _, quoted := hasQuotePrefix(src); quoted {
+		return true
+	}
+	return blockDelimiter(src) != nil
+}
+
 func indexOfNonSpaceChar(src []byte) int {
 	return bytes.IndexFunc(src, func(r rune) bool {
 		return !unicode.IsSpace(r)
@@ -249,12 +816,93 @@ func isLineEnd(r rune) bool {
 }
 
 var (
-	escapeRegex        = regexp.MustCompile(`\\.`)
-	expandVarRegex     = regexp.MustCompile(`(\\)?(\$)(\()?\{?([A-Z0-9_]+)?\}?`)
+	escapeRegex = regexp.MustCompile(`\\.`)
+	// expandVarRegex matches ${VAR}, $VAR and unterminated ${VAR references.
+	expandVarRegex = regexp.MustCompile(`(\\)?(\$)(\()?(?:\{([A-Z0-9_]+)\}|(\{)?([A-Z0-9_]+)?(\})?)`)
+	// defaultExprRegex matches the start of a ${VAR:-word} or ${VAR:+word}
+	// reference, whose word may hold references of its own.
+	defaultExprRegex   = regexp.MustCompile(`(\\)?\$\{([A-Z0-9_]+):([-+])`)
 	unescapeCharsRegex = regexp.MustCompile(`\\([^$])`)
 )
 
-func expandVariables(v string, m map[string]string) string {
+// expandVariables expands the references of v, failing on ${VAR:-word} and
+// ${VAR:+word} references missing their closing brace.
+func expandVariables(v string, m map[string]string, opts parseOptions) (string, error) {
+	if opts.expansion == expandNone {
+		return expandReferences(v, m, opts), nil
+	}
+
+	var out strings.Builder
+	for {
+		loc := defaultExprRegex.FindStringSubmatchIndex(v)
+		if loc == nil {
+			out.WriteString(expandReferences(v, m, opts))
+			return out.String(), nil
+		}
+		out.WriteString(expandReferences(v[:loc[0]], m, opts))
+
+		escaped := loc[2] != -1
+		end := closingBrace(v, loc[1])
+		switch {
+		case escaped && end == -1:
+			out.WriteString(v[loc[0]+1 : loc[1]])
+			v = v[loc[1]:]
+			continue
+		case escaped:
+			out.WriteString(v[loc[0]+1 : end+1])
+			v = v[end+1:]
+			continue
+		case end == -1:
+			return "", fmt.Errorf("unbalanced braces in %q", v[loc[0]:])
+		}
+
+		key, operator, word := v[loc[4]:loc[5]], v[loc[6]:loc[7]], v[loc[1]:end]
+		value, _ := opts.lookup(key, m)
+		if opts.onReference != nil {
+			// a variable with a fallback resolves even when it is missing
+			opts.onReference(key, true)
+		}
+
+		// as in the shell, ${VAR:-word} falls back to word when VAR is unset or
+		// empty, while ${VAR:+word} only gives word when VAR is set and not empty,
+		// word being expanded in turn so that fallbacks can be nested
+		useWord := value == ""
+		if operator == "+" {
+			useWord = !useWord
+			value = ""
+		}
+		if useWord {
+			expanded, err := expandVariables(word, m, opts)
+			if err != nil {
+				return "", err
+			}
+			value = expanded
+		}
+		out.WriteString(value)
+		v = v[end+1:]
+	}
+}
+
+// closingBrace returns the index of the brace closing the ${ reference whose
+// content starts at start in v, skipping nested references, or -1 if there is none.
+func closingBrace(v string, start int) int {
+	depth := 0
+	for i := start; i < len(v); i++ {
+		switch {
+		case strings.HasPrefix(v[i:], "${"):
+			depth++
+			i++
+		case v[i] == '}' && depth == 0:
+			return i
+		case v[i] == '}':
+			depth--
+		}
+	}
+	return -1
+}
+
+// expandReferences expands the ${VAR} and $VAR references of v.
+func expandReferences(v string, m map[string]string, opts parseOptions) string {
 	return expandVarRegex.ReplaceAllStringFunc(v, func(s string) string {
 		submatch := expandVarRegex.FindStringSubmatch(s)
 
@@ -263,9 +911,20 @@ func expandVariables(v string, m map[string]string) string {
 		}
 		if submatch[1] == "\\" || submatch[2] == "(" {
 			return submatch[0][1:]
-		} else if submatch[4] != "" {
-			return m[submatch[4]]
 		}
-		return s
+		braced := submatch[4] != ""
+		if opts.expansion == expandNone || (opts.expansion == expandBraces && !braced) {
+			return s
+		}
+
+		key := submatch[4] + submatch[6]
+		if key == "" {
+			return s
+		}
+		value, ok := opts.lookup(key, m)
+		if opts.onReference != nil {
+			opts.onReference(key, ok)
+		}
+		return value
 	})
 }
@@ -0,0 +1,61 @@
+package godotenv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces the values of secret keys in the messages of the package.
+const RedactedValue = "***"
+
+var (
+	secretKeysMu sync.RWMutex
+	secretKeys   *regexp.Regexp
+)
+
+// RedactKeys marks the keys matching pattern as secrets, whose values are replaced
+// by *** in the error messages of the package, such as those of ParseError,
+// Validate, Decode and the getters, as well as in the rendering of Change.
+//
+//	godotenv.RedactKeys(regexp.MustCompile(`PASSWORD|TOKEN|SECRET`))
+//
+// The values themselves are left intact. A nil pattern stops redacting values.
+func RedactKeys(pattern *regexp.Regexp) {
+	secretKeysMu.Lock()
+	defer secretKeysMu.Unlock()
+	secretKeys = pattern
+}
+
+// Redact returns value, or *** when key was marked as a secret by RedactKeys.
+//
+// It is meant to render values safely, such as those returned by Diff.
+func Redact(key, value string) string {
+	if isSecret(key) {
+		return RedactedValue
+	}
+	return value
+}
+
+func isSecret(key string) bool {
+	secretKeysMu.RLock()
+	defer secretKeysMu.RUnlock()
+	return secretKeys != nil && secretKeys.MatchString(key)
+}
+
+// quoteValue quotes value for an error message, unless key is a secret.
+func quoteValue(key, value string) string {
+	if isSecret(key) {
+		return RedactedValue
+	}
+	return strconv.Quote(value)
+}
+
+// redactMessage removes value from msg when key is a secret.
+func redactMessage(key, value, msg string) string {
+	if value == "" || !isSecret(key) {
+		return msg
+	}
+	return strings.Replace(msg, value, RedactedValue, -1)
+}
@@ -0,0 +1,53 @@
+package godotenv
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	RedactKeys(regexp.MustCompile(`PASSWORD|TOKEN`))
+	defer RedactKeys(nil)
+
+	if got := Redact("DB_PASSWORD", "hunter2"); got != RedactedValue {
+		t.Errorf("Expected DB_PASSWORD to be redacted, got %q", got)
+	}
+	if got := Redact("DB_HOST", "localhost"); got != "localhost" {
+		t.Errorf("Expected DB_HOST to be left alone, got %q", got)
+	}
+
+	RedactKeys(nil)
+	if got := Redact("DB_PASSWORD", "hunter2"); got != "hunter2" {
+		t.Errorf("Expected redaction to stop, got %q", got)
+	}
+}
+
+func TestRedactedMessages(t *testing.T) {
+	RedactKeys(regexp.MustCompile(`PASSWORD|TOKEN`))
+	defer RedactKeys(nil)
+
+	errs := Validate(map[string]string{"API_TOKEN": "s3cr3t"}, Schema{
+		"API_TOKEN": {Type: Int},
+	})
+	if len(errs) != 1 || strings.Contains(errs[0].Error(), "s3cr3t") {
+		t.Errorf("Expected the token to be redacted from %v", errs)
+	}
+
+	os.Clearenv()
+	os.Setenv("DB_PASSWORD", "hunter2")
+	if _, err := GetInt("DB_PASSWORD"); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected the password to be redacted from %v", err)
+	}
+
+	_, err := Unmarshal("DB_PASSWORD=\"hunter2")
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected the password to be redacted from %v", err)
+	}
+
+	change := Change{Key: "DB_PASSWORD", Old: "hunter2", New: "hunter3", Set: true}
+	if s := change.String(); strings.Contains(s, "hunter") {
+		t.Errorf("Expected the password to be redacted from %q", s)
+	}
+}
@@ -0,0 +1,133 @@
+package godotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadEncrypted is like Load, for env files stored encrypted, such as a .env.enc
+// file, which are decrypted in memory by decrypt before being parsed:
+//
+//	err := godotenv.LoadEncrypted(func(ciphertext []byte) ([]byte, error) {
+//		return decryptWithKMS(ctx, ciphertext)
+//	}, true, ".env.enc")
+//
+// The package doesn't bundle any cryptography, decrypt is where tools such as
+// SOPS, age or a KMS client are plugged in. The plaintext is never written to
+// disk. The files imported with @import are expected to be encrypted as well.
+func LoadEncrypted(decrypt func(ciphertext []byte) ([]byte, error), strict bool, filenames ...string) error {
+	_, err := loadFiles(openDecrypted(openFrom("./"), decrypt), strict, false, filenames, loadOptions{})
+	return err
+}
+
+// openDecrypted returns an openFunc opening the files with open and giving the
+// content decrypted by decrypt.
+func openDecrypted(open openFunc, decrypt func([]byte) ([]byte, error)) openFunc {
+	return func(name string) (fs.File, error) {
+		file, err := open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		ciphertext, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := decrypt(ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("%s: decrypting: %w", name, err)
+		}
+		return &memFile{Reader: bytes.NewReader(plaintext), info: info}, nil
+	}
+}
+
+// memFile is an fs.File whose content is held in memory.
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+// LoadDir loads the regular files of dir as variables, each named after its file
+// and set to its content without its trailing newline, as with the
+// dir/DB_PASSWORD convention of systemd credentials and Kubernetes volumes.
+//
+// Hidden files and everything that isn't a readable regular file once symlinks
+// are followed, such as subdirectories and dangling symlinks, are skipped, so
+// that a single stray entry doesn't keep the others from loading. A trailing
+// newline is left out whether it is \n or \r\n. As with Overload, variables
+// that are already set are only overridden when overload is true.
+func LoadDir(dir string, overload bool) error {
+	return loadDir(dir, overload, loadOptions{})
+}
+
+// LoadSecretsDir is like LoadDir with overload false, for the secrets mounted by
+// Docker in /run/secrets or by a Kubernetes secret volume, with the values
+// tweaked by the given options:
+//
+//	err := godotenv.LoadSecretsDir("/run/secrets", godotenv.CaseFold())
+//
+// As the tools writing them often end the files with a newline, a single one is
+// left out of the values unless the KeepTrailingNewline option is given.
+func LoadSecretsDir(dir string, opts ...LoadOption) error {
+	return loadDir(dir, false, newLoadOptions(opts))
+}
+
+func loadDir(dir string, overload bool, opts loadOptions) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	opts.strict = true
+	var pairs []Pair
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := filepath.Join(dir, entry.Name())
+		// secret volumes hold symlinks to the files, which Stat follows
+		info, err := os.Stat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		value := string(content)
+		if !opts.keepNewline {
+			value = trimNewline(value)
+		}
+		pairs = append(pairs, Pair{Key: entry.Name(), Value: value})
+	}
+
+	var result Result
+	return loadPairs(dir, pairs, overload, opts, &result)
+}
+
+// trimNewline returns s without a single trailing newline.
+func trimNewline(s string) string {
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2]
+	}
+	return strings.TrimSuffix(s, "\n")
+}
@@ -0,0 +1,144 @@
+package godotenv
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadSecretsDir(t *testing.T) {
+	dir := t.TempDir()
+	secrets := map[string]string{
+		"DB_PASSWORD": "hunter2\n",
+		"API_TOKEN":   "token",
+		"MOTD":        "hello\n\n",
+		"CRLF":        "windows\r\n",
+		".hidden":     "skipped",
+	}
+	for name, content := range secrets {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.WriteFile(filepath.Join(dir, "..data", "LINKED"), []byte("linked\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..data", "LINKED"), filepath.Join(dir, "LINKED")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..data", "GONE"), filepath.Join(dir, "DANGLING")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("API_TOKEN", "preset")
+	if err := LoadSecretsDir(dir); err != nil {
+		t.Fatalf("Error loading secrets: %v", err)
+	}
+
+	expected := map[string]string{"DB_PASSWORD": "hunter2", "API_TOKEN": "preset", "MOTD": "hello\n", "CRLF": "windows"}
+	if runtime.GOOS != "windows" {
+		expected["LINKED"] = "linked"
+	}
+	for key, value := range expected {
+		if actual := os.Getenv(key); actual != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, actual)
+		}
+	}
+	for _, key := range []string{".hidden", "nested", "..data", "DANGLING"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Errorf("Expected %s to be skipped", key)
+		}
+	}
+
+	os.Clearenv()
+	if err := LoadSecretsDir(dir, KeepTrailingNewline()); err != nil {
+		t.Fatalf("Error loading secrets: %v", err)
+	}
+	if actual := os.Getenv("DB_PASSWORD"); actual != "hunter2\n" {
+		t.Errorf("Expected the trailing newline to be kept, got %q", actual)
+	}
+
+	if err := LoadSecretsDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected a missing directory to fail")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"HOST": "localhost\n", "PORT": "8080"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("PORT", "preset")
+	if err := LoadDir(dir, false); err != nil {
+		t.Fatalf("Error loading dir: %v", err)
+	}
+	if os.Getenv("HOST") != "localhost" || os.Getenv("PORT") != "preset" {
+		t.Errorf("Expected HOST to be loaded and PORT kept, got %q, %q", os.Getenv("HOST"), os.Getenv("PORT"))
+	}
+
+	if err := LoadDir(dir, true); err != nil {
+		t.Fatalf("Error loading dir: %v", err)
+	}
+	if os.Getenv("PORT") != "8080" {
+		t.Errorf("Expected PORT to be overridden, got %q", os.Getenv("PORT"))
+	}
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	// a stand-in for real encryption, reversing the bytes of the file
+	reverse := func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i, c := range b {
+			out[len(b)-1-i] = c
+		}
+		return out
+	}
+	decrypt := func(ciphertext []byte) ([]byte, error) {
+		if !bytes.HasPrefix(ciphertext, []byte("ENC:")) {
+			return nil, errors.New("not encrypted")
+		}
+		return reverse(ciphertext[len("ENC:"):]), nil
+	}
+
+	dir := t.TempDir()
+	encrypted := append([]byte("ENC:"), reverse([]byte("DB_PASSWORD=hunter2\nDB_USER=app\n"))...)
+	if err := os.WriteFile(filepath.Join(dir, ".env.enc"), encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plain.env"), []byte("A=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+
+	os.Clearenv()
+	if err := LoadEncrypted(decrypt, true, ".env.enc"); err != nil {
+		t.Fatalf("Error loading encrypted file: %v", err)
+	}
+	if os.Getenv("DB_PASSWORD") != "hunter2" || os.Getenv("DB_USER") != "app" {
+		t.Errorf("Expected the decrypted values to be loaded, got %q, %q", os.Getenv("DB_PASSWORD"), os.Getenv("DB_USER"))
+	}
+
+	if err := LoadEncrypted(decrypt, true, "plain.env"); err == nil || !strings.Contains(err.Error(), "not encrypted") {
+		t.Errorf("Expected the decryption error to be returned, got %v", err)
+	}
+	if err := LoadEncrypted(decrypt, true, "missing.env"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected a missing file to be reported as such, got %v", err)
+	}
+}
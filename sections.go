@@ -0,0 +1,84 @@
+package godotenv
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultSection groups the keys declared before any section header.
+const DefaultSection = ""
+
+// defaultSectionHeader matches comments such as "# --- Database ---" or "# === Cache ===".
+var defaultSectionHeader = regexp.MustCompile(`^#+\s*[-=]{3,}\s*(.+?)\s*[-=]{3,}$`)
+
+// ParseSections is like ParseWithOptions, but also groups the keys of the file in
+// sections started by header comments:
+//
+//	# --- Database ---
+//	DB_HOST=localhost
+//	DB_PORT=5432
+//
+// A comment line matching header starts a new section, named after the first
+// submatch of header or, when it has none, after the comment text. A nil header
+// matches comments made of a name surrounded by at least three - or =.
+//
+// Each section lists its keys in the order they are first declared, keys declared
+// before any header being listed under DefaultSection.
+func ParseSections(r io.Reader, header *regexp.Regexp, opts ...ParseOption) (envMap map[string]string, sections map[string][]string, err error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, nil, err
+	}
+	src := normalizeSource(buf.Bytes())
+
+	o := newParseOptions(opts)
+	pairs, err := parseBytes(src, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	statements, err := scanStatements(src, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header == nil {
+		header = defaultSectionHeader
+	}
+	sections = make(map[string][]string)
+	seen := make(map[string]bool)
+	section := DefaultSection
+	for _, statement := range statements {
+		if statement.key == "" {
+			if name, ok := sectionName(header, string(src[statement.start:statement.end])); ok {
+				section = name
+			}
+			continue
+		}
+		if seen[statement.key] {
+			continue
+		}
+		seen[statement.key] = true
+		sections[section] = append(sections[section], statement.key)
+	}
+
+	envMap = make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		envMap[pair.Key] = pair.Value
+	}
+	return envMap, sections, nil
+}
+
+// sectionName reports whether comment is a section header and returns its name.
+func sectionName(header *regexp.Regexp, comment string) (string, bool) {
+	comment = strings.TrimRightFunc(comment, isSpace)
+	submatch := header.FindStringSubmatch(comment)
+	if submatch == nil {
+		return "", false
+	}
+	if len(submatch) > 1 && submatch[1] != "" {
+		return submatch[1], true
+	}
+	return strings.TrimSpace(strings.TrimLeft(comment, "#")), true
+}
@@ -0,0 +1,54 @@
+package godotenv
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	input := "APP_NAME=godotenv\n" +
+		"# not a header\n" +
+		"# --- Database ---\n" +
+		"DB_HOST=localhost\n" +
+		"DB_PORT=5432 # inline comment\n" +
+		"\n" +
+		"# === Cache ===\n" +
+		"CACHE_URL=\"redis://localhost\n# --- not a header ---\"\n" +
+		"DB_HOST=db.internal\n"
+
+	envMap, sections, err := ParseSections(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("Expected input to parse, got %v", err)
+	}
+
+	if envMap["DB_HOST"] != "db.internal" {
+		t.Errorf("Expected the last value of DB_HOST, got %q", envMap["DB_HOST"])
+	}
+	expected := map[string][]string{
+		DefaultSection: {"APP_NAME"},
+		"Database":     {"DB_HOST", "DB_PORT"},
+		"Cache":        {"CACHE_URL"},
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected sections %v, got %v", expected, sections)
+	}
+}
+
+func TestParseSectionsCustomHeader(t *testing.T) {
+	input := "## Database\nDB_HOST=localhost\n# plain comment\nDB_PORT=5432\n## Cache\nCACHE_URL=redis://localhost"
+
+	_, sections, err := ParseSections(strings.NewReader(input), regexp.MustCompile(`^##\s`))
+	if err != nil {
+		t.Fatalf("Expected input to parse, got %v", err)
+	}
+
+	expected := map[string][]string{
+		"Database": {"DB_HOST", "DB_PORT"},
+		"Cache":    {"CACHE_URL"},
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected sections %v, got %v", expected, sections)
+	}
+}
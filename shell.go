@@ -0,0 +1,122 @@
+package godotenv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToShell renders the given environment as shell commands exporting it, one
+// export KEY='VALUE' line per key in sorted order, for a CLI to print them to be
+// evaluated by the calling shell:
+//
+//	eval "$(mytool env)"
+//
+// Values are single quoted, so that the shell takes them literally. An error is
+// returned for the keys that aren't valid shell names, which Normalize fixes, and
+// for the values holding carriage returns.
+func ToShell(envMap map[string]string) (string, error) {
+	for _, key := range sortedKeys(envMap) {
+		if !isPortableKey(key) {
+			return "", fmt.Errorf("invalid variable name %q, expected letters, digits and _ not starting with a digit", key)
+		}
+	}
+	if len(envMap) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	if err := MarshalTo(&sb, envMap, ExportPrefix(), ShellQuoting()); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// ToPowerShell renders the given environment as PowerShell commands setting it,
+// one $env:KEY = 'VALUE' line per key in sorted order, to be evaluated with
+// Invoke-Expression:
+//
+//	mytool env | Out-String | Invoke-Expression
+//
+// Values are single quoted, which PowerShell takes literally, newlines included.
+// The quotes they hold are doubled, including the typographic ones ‘ ’ ‚ ‛ that
+// PowerShell also takes for single quotes.
+func ToPowerShell(envMap map[string]string) string {
+	var b strings.Builder
+	for _, key := range sortedKeys(envMap) {
+		name := "$env:" + key
+		if !isPortableKey(key) {
+			name = "${env:" + powerShellBraceEscaper.Replace(key) + "}"
+		}
+		b.WriteString(name + " = '" + powerShellQuoteEscaper.Replace(envMap[key]) + "'\r\n")
+	}
+	return b.String()
+}
+
+// powerShellQuoteEscaper doubles the characters PowerShell reads as single quotes.
+var powerShellQuoteEscaper = strings.NewReplacer(
+	"'", "''",
+	"\u2018", "\u2018\u2018",
+	"\u2019", "\u2019\u2019",
+	"\u201A", "\u201A\u201A",
+	"\u201B", "\u201B\u201B",
+)
+
+// powerShellBraceEscaper escapes the characters that can't be written as they
+// are in a ${...} variable name.
+var powerShellBraceEscaper = strings.NewReplacer("`", "``", "{", "`{", "}", "`}")
+
+// ToCmd renders the given environment as cmd.exe commands setting it, one
+// set "KEY=VALUE" line per key in sorted order, to be written to a batch file
+// and run with call:
+//
+//	mytool env > env.bat && call env.bat
+//
+// Percent signs are doubled, as batch files require. cmd.exe can't set values
+// holding newlines, for which an error is returned, nor values holding ! when
+// delayed expansion is enabled.
+func ToCmd(envMap map[string]string) (string, error) {
+	var b strings.Builder
+	for _, key := range sortedKeys(envMap) {
+		value := envMap[key]
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("%s: cmd.exe can't set values spanning several lines", key)
+		}
+		b.WriteString("set \"" + cmdEscape(key+"="+value) + "\"\r\n")
+	}
+	return b.String(), nil
+}
+
+// cmdEscape escapes s for it to be read as is between the quotes of a
+// set "KEY=VALUE" command. The quotes s holds toggle whether cmd.exe reads the
+// special characters that follow literally, so those found outside of quotes
+// are escaped with a caret, which is itself read literally within quotes. The
+// last quote of the line ends the value, so the ones s holds are kept.
+func cmdEscape(s string) string {
+	var b strings.Builder
+	quoted := true
+	for _, c := range s {
+		switch c {
+		case '%':
+			b.WriteString("%%")
+			continue
+		case '"':
+			quoted = !quoted
+		case '&', '|', '<', '>', '^', '(', ')':
+			if !quoted {
+				b.WriteByte('^')
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of envMap in sorted order.
+func sortedKeys(envMap map[string]string) []string {
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package godotenv
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestToShell(t *testing.T) {
+	envMap := map[string]string{"B": "it's", "A": "$HOME\nline", "C": `'\; echo INJECTED #`}
+	expected := "export A='$HOME\nline'\nexport B='it'\\''s'\nexport C=''\\''\\; echo INJECTED #'\n"
+	actual, err := ToShell(envMap)
+	if err != nil {
+		t.Fatalf("Expected env to render, got %v", err)
+	}
+	if actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	for _, key := range []string{"X=1; rm -rf ~; Y", "1X", "A B", ""} {
+		if _, err := ToShell(map[string]string{key: "v"}); err == nil {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on /bin/sh")
+	}
+	out, err := exec.Command("/bin/sh", "-c", `eval "$0" && printf '%s|%s|%s' "$A" "$B" "$C"`, actual).Output()
+	if err != nil {
+		t.Fatalf("Expected the output to be evaluated, got %v", err)
+	}
+	if string(out) != "$HOME\nline|it's|'\\; echo INJECTED #" {
+		t.Errorf("Expected the shell to read the values as they are, got %q", out)
+	}
+}
+
+func TestToPowerShell(t *testing.T) {
+	envMap := map[string]string{
+		"QUOTE":   `it's "quoted"`,
+		"SPECIAL": "$HOME `cmd` 50%",
+		"LINES":   "a\nb",
+		"DB.HOST": "localhost",
+		"ODD{}":   "x",
+		"SMART":   "it\u2019s\u2018; calc \u201A\u201B",
+	}
+	expected := "${env:DB.HOST} = 'localhost'\r\n" +
+		"$env:LINES = 'a\nb'\r\n" +
+		"${env:ODD`{`}} = 'x'\r\n" +
+		"$env:QUOTE = 'it''s \"quoted\"'\r\n" +
+		"$env:SMART = 'it\u2019\u2019s\u2018\u2018; calc \u201A\u201A\u201B\u201B'\r\n" +
+		"$env:SPECIAL = '$HOME `cmd` 50%'\r\n"
+	if actual := ToPowerShell(envMap); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestToCmd(t *testing.T) {
+	envMap := map[string]string{
+		"PERCENT": "100%",
+		"SPECIAL": "a&b|c<d>e^f",
+		"QUOTE":   `say "a&b" & c`,
+		"ODD":     `5" & b`,
+	}
+	expected := `set "ODD=5" ^& b"` + "\r\n" +
+		`set "PERCENT=100%%"` + "\r\n" +
+		`set "QUOTE=say "a^&b" & c"` + "\r\n" +
+		`set "SPECIAL=a&b|c<d>e^f"` + "\r\n"
+	actual, err := ToCmd(envMap)
+	if err != nil {
+		t.Fatalf("Expected env to render, got %v", err)
+	}
+	if actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	if _, err := ToCmd(map[string]string{"CERT": "line1\nline2"}); err == nil || !strings.Contains(err.Error(), "CERT") {
+		t.Errorf("Expected a multiline value to fail, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package godotenv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MissingKeysError reports the required keys that are missing from the environment.
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return "missing required env vars: " + strings.Join(e.Keys, ", ")
+}
+
+// Require checks that every one of keys is set in the environment, even if to an
+// empty value. When some aren't, it returns a *MissingKeysError listing all of them.
+func Require(keys ...string) error {
+	return require(keys, false)
+}
+
+// RequireNonEmpty is like Require, but also treats keys set to an empty value as missing.
+func RequireNonEmpty(keys ...string) error {
+	return require(keys, true)
+}
+
+func require(keys []string, nonEmpty bool) error {
+	var missing []string
+	for _, key := range keys {
+		value, ok := os.LookupEnv(key)
+		if !ok || (nonEmpty && value == "") {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// FieldType is the type a value must be parsable as to satisfy a FieldSpec.
+type FieldType int
+
+const (
+	// String accepts any value.
+	String FieldType = iota
+	// Int accepts integers.
+	Int
+	// Float accepts floating point numbers.
+	Float
+	// Bool accepts the values GetBool does.
+	Bool
+	// Duration accepts values time.ParseDuration does.
+	Duration
+)
+
+// describe names the type for error messages.
+func (t FieldType) describe() string {
+	switch t {
+	case Int:
+		return "an int"
+	case Float:
+		return "a float"
+	case Bool:
+		return "a bool"
+	case Duration:
+		return "a duration"
+	default:
+		return "a string"
+	}
+}
+
+// FieldSpec describes the acceptable values of a key.
+type FieldSpec struct {
+	// Required keys must be declared, other keys are only checked when they are.
+	Required bool
+	// Type is the type the value must be parsable as.
+	Type FieldType
+	// Min and Max, when not nil, are the inclusive bounds of the value of Int and
+	// Float keys. Either can be left nil for a one-sided range.
+	Min, Max *float64
+	// Enum, when not empty, lists the accepted values.
+	Enum []string
+	// Pattern, when not nil, must match the value.
+	Pattern *regexp.Regexp
+}
+
+// Bound returns a pointer to v, to be used as the Min or Max of a FieldSpec.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// Schema maps keys to the specification of their acceptable values.
+type Schema map[string]FieldSpec
+
+// ValidationError reports a value that doesn't satisfy its FieldSpec.
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// Validate checks envMap against schema and returns every violation found, as
+// *ValidationError values ordered by key. Keys missing from schema aren't checked.
+//
+//	errs := godotenv.Validate(envMap, godotenv.Schema{
+//		"PORT": {Required: true, Type: godotenv.Int, Min: godotenv.Bound(1), Max: godotenv.Bound(65535)},
+//		"ENV":  {Enum: []string{"dev", "prod"}},
+//	})
+func Validate(envMap map[string]string, schema Schema) []error {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		spec := schema[key]
+		value, ok := envMap[key]
+		if !ok {
+			if spec.Required {
+				errs = append(errs, &ValidationError{Key: key, Message: "is required"})
+			}
+			continue
+		}
+
+		if message := spec.check(key, value); message != "" {
+			errs = append(errs, &ValidationError{Key: key, Message: message})
+		}
+	}
+	return errs
+}
+
+// check returns why the value of key doesn't satisfy the spec, or an empty string
+// if it does.
+func (spec FieldSpec) check(key, value string) string {
+	var number float64
+	var err error
+	switch spec.Type {
+	case Int:
+		var n int
+		n, err = strconv.Atoi(value)
+		number = float64(n)
+	case Float:
+		number, err = strconv.ParseFloat(value, 64)
+	case Bool:
+		_, err = parseBool(value)
+	case Duration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Sprintf("%s is not %s", quoteValue(key, value), spec.Type.describe())
+	}
+
+	if spec.Type == Int || spec.Type == Float {
+		switch {
+		case spec.Min != nil && spec.Max != nil && (number < *spec.Min || number > *spec.Max):
+			return fmt.Sprintf("%s is not between %v and %v", quoteValue(key, value), *spec.Min, *spec.Max)
+		case spec.Min != nil && number < *spec.Min:
+			return fmt.Sprintf("%s is less than %v", quoteValue(key, value), *spec.Min)
+		case spec.Max != nil && number > *spec.Max:
+			return fmt.Sprintf("%s is greater than %v", quoteValue(key, value), *spec.Max)
+		}
+	}
+	if len(spec.Enum) > 0 && !containsString(spec.Enum, value) {
+		return fmt.Sprintf("%s is not one of %s", quoteValue(key, value), strings.Join(spec.Enum, ", "))
+	}
+	if spec.Pattern != nil && !spec.Pattern.MatchString(value) {
+		return fmt.Sprintf("%s does not match %s", quoteValue(key, value), spec.Pattern)
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
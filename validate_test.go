@@ -0,0 +1,130 @@
+package godotenv
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRequire(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SET", "value")
+	os.Setenv("EMPTY", "")
+
+	if err := Require("SET", "EMPTY"); err != nil {
+		t.Errorf("Expected set keys to be accepted, got %v", err)
+	}
+
+	err := Require("MISSING_A", "SET", "EMPTY", "MISSING_B")
+	missingErr, ok := err.(*MissingKeysError)
+	if !ok {
+		t.Fatalf("Expected a *MissingKeysError, got %#v", err)
+	}
+	if expected := []string{"MISSING_A", "MISSING_B"}; !reflect.DeepEqual(missingErr.Keys, expected) {
+		t.Errorf("Expected missing keys %v, got %v", expected, missingErr.Keys)
+	}
+	if err.Error() != "missing required env vars: MISSING_A, MISSING_B" {
+		t.Errorf("Unexpected error message %q", err)
+	}
+}
+
+func TestRequireNonEmpty(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SET", "value")
+	os.Setenv("EMPTY", "")
+
+	if err := RequireNonEmpty("SET"); err != nil {
+		t.Errorf("Expected set keys to be accepted, got %v", err)
+	}
+
+	err := RequireNonEmpty("SET", "EMPTY", "MISSING")
+	missingErr, ok := err.(*MissingKeysError)
+	if !ok {
+		t.Fatalf("Expected a *MissingKeysError, got %#v", err)
+	}
+	if expected := []string{"EMPTY", "MISSING"}; !reflect.DeepEqual(missingErr.Keys, expected) {
+		t.Errorf("Expected missing keys %v, got %v", expected, missingErr.Keys)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := Schema{
+		"PORT":    {Required: true, Type: Int, Min: Bound(1), Max: Bound(65535)},
+		"ENV":     {Enum: []string{"dev", "prod"}},
+		"RATIO":   {Type: Float, Min: Bound(0), Max: Bound(1)},
+		"DEBUG":   {Type: Bool},
+		"TIMEOUT": {Type: Duration},
+		"NAME":    {Required: true, Pattern: regexp.MustCompile(`^[a-z]+$`)},
+		"UNUSED":  {Type: Int},
+	}
+
+	valid := map[string]string{
+		"PORT":    "8080",
+		"ENV":     "prod",
+		"RATIO":   "0.5",
+		"DEBUG":   "on",
+		"TIMEOUT": "5s",
+		"NAME":    "godotenv",
+		"EXTRA":   "not in schema",
+	}
+	if errs := Validate(valid, schema); len(errs) != 0 {
+		t.Errorf("Expected no violations, got %v", errs)
+	}
+
+	invalid := map[string]string{
+		"PORT":    "notanumber",
+		"ENV":     "staging",
+		"RATIO":   "1.5",
+		"DEBUG":   "maybe",
+		"TIMEOUT": "5",
+	}
+	var actual []string
+	for _, err := range Validate(invalid, schema) {
+		actual = append(actual, err.Error())
+	}
+	expected := []string{
+		`DEBUG: "maybe" is not a bool`,
+		`ENV: "staging" is not one of dev, prod`,
+		`NAME: is required`,
+		`PORT: "notanumber" is not an int`,
+		`RATIO: "1.5" is not between 0 and 1`,
+		`TIMEOUT: "5" is not a duration`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected violations %q, got %q", expected, actual)
+	}
+
+	if errs := Validate(map[string]string{"PORT": "0", "NAME": "Go"}, schema); len(errs) != 2 {
+		t.Errorf("Expected range and pattern violations, got %v", errs)
+	}
+}
+
+func TestValidateOneSidedBounds(t *testing.T) {
+	schema := Schema{
+		"WORKERS": {Type: Int, Min: Bound(1)},
+		"OFFSET":  {Type: Int, Min: Bound(-5)},
+		"RETRIES": {Type: Int, Max: Bound(3)},
+		"RATE":    {Type: Float, Max: Bound(-0.5)},
+	}
+
+	valid := map[string]string{"WORKERS": "1000", "OFFSET": "-5", "RETRIES": "-10", "RATE": "-1"}
+	if errs := Validate(valid, schema); len(errs) != 0 {
+		t.Errorf("Expected no violations, got %v", errs)
+	}
+
+	invalid := map[string]string{"WORKERS": "0", "OFFSET": "-6", "RETRIES": "4", "RATE": "0"}
+	var actual []string
+	for _, err := range Validate(invalid, schema) {
+		actual = append(actual, err.Error())
+	}
+	expected := []string{
+		`OFFSET: "-6" is less than -5`,
+		`RATE: "0" is greater than -0.5`,
+		`RETRIES: "4" is greater than 3`,
+		`WORKERS: "0" is less than 1`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected violations %q, got %q", expected, actual)
+	}
+}